@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/kaienkira/brickred-table-compiler-v2/compiler/internal"
+	flag "github.com/spf13/pflag"
+)
+
+var g_diagnostics = NewDiagnosticList()
+var g_diagnosticsFormat = DiagnosticFormat_Text
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, ""+
+		"brickred table checker\n"+
+		"usage: %s "+
+		"-f <define_file>"+
+		"\n"+
+		"    [-i <input_dir>] check data files in the directory\n"+
+		"    [-r <reader>] input_dir contains data cut for the reader\n"+
+		"    [--lint] report define file warnings\n"+
+		"    [--check-chars] report full-width and invisible characters\n"+
+		"    [--fix-chars] normalize them in data files\n"+
+		"    [--diagnostics-format <format>] "+
+		"(text|json|sarif) default is text\n",
+		filepath.Base(os.Args[0]))
+}
+
+func run() int {
+	// parse command line options
+	var optHelp bool
+	var optDefineFilePath string
+	var optReader string
+	var optInputDir string
+	var optLint bool
+	var optCheckChars bool
+	var optFixChars bool
+	var optDiagnosticsFormat string
+
+	flagSet := flag.NewFlagSet("main", flag.ContinueOnError)
+	flagSet.BoolVarP(&optHelp, "help", "h", false, "")
+	flagSet.StringVarP(&optDefineFilePath, "-define_file_path", "f", "", "")
+	flagSet.StringVarP(&optReader, "-reader", "r", "", "")
+	flagSet.StringVarP(&optInputDir, "-input_dir", "i", "", "")
+	flagSet.BoolVar(&optLint, "lint", false, "")
+	flagSet.BoolVar(&optCheckChars, "check-chars", false, "")
+	flagSet.BoolVar(&optFixChars, "fix-chars", false, "")
+	flagSet.StringVar(&optDiagnosticsFormat, "diagnostics-format", "", "")
+
+	if flagSet.Parse(os.Args[1:]) != nil {
+		printUsage()
+		return 1
+	}
+	if optHelp {
+		printUsage()
+		return 0
+	}
+
+	// check command line options
+	// -- required options
+	if optDefineFilePath == "" ||
+		(optInputDir == "" && optLint == false) {
+		printUsage()
+		return 1
+	}
+	// -- option default value
+	if optDiagnosticsFormat == "" {
+		optDiagnosticsFormat = "text"
+	}
+
+	// -- check option diagnostics_format
+	g_diagnosticsFormat = ParseDiagnosticFormat(optDiagnosticsFormat)
+	if g_diagnosticsFormat == DiagnosticFormat_None {
+		g_diagnosticsFormat = DiagnosticFormat_Text
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"diagnostics_format `%s` is invalid",
+			optDiagnosticsFormat)
+		return 1
+	}
+
+	// -- check option define_file_path
+	if UtilCheckFileExists(optDefineFilePath) == false {
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"can not find define file `%s`",
+			optDefineFilePath)
+		return 1
+	}
+
+	// -- check option input_dir
+	if optInputDir != "" &&
+		UtilCheckDirExists(optInputDir) == false {
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"can not find input directory `%s`",
+			optInputDir)
+		return 1
+	}
+
+	// create parser
+	parser := NewTableParser()
+	defer parser.Close()
+	parseOk := parser.Parse(optDefineFilePath)
+	if parseOk && optLint {
+		// lint before reader filter, so all readers are considered
+		linter := NewTableLinter(parser.Descriptor)
+		linter.Lint()
+		g_diagnostics.Append(linter.Diagnostics)
+		linter.Close()
+	}
+	if parseOk && optReader != "" {
+		parseOk = parser.FilterByReader(optReader)
+	}
+	g_diagnostics.Append(parser.Diagnostics)
+	if parseOk == false {
+		return 1
+	}
+	if optInputDir == "" {
+		return 0
+	}
+	if optReader != "" {
+		// cut data of each table is merged into one file
+		for _, def := range parser.Descriptor.Tables {
+			def.SourceFileNames = []string{def.FileName}
+		}
+	}
+
+	if checkTables(parser.Descriptor, optInputDir,
+		optCheckChars || optFixChars, optFixChars) == false {
+		return 1
+	}
+
+	return 0
+}
+
+func checkTables(descriptor *TableDescriptor, inputDir string,
+	checkChars bool, fixChars bool) bool {
+
+	dataReader := NewTableDataReader(inputDir)
+	defer dataReader.Close()
+	charChecker := NewTableDataCharChecker(fixChars)
+	defer charChecker.Close()
+	dataChecker := NewTableDataChecker(descriptor)
+	defer dataChecker.Close()
+
+	tableDatas := make([]*TableData, 0, len(descriptor.Tables))
+	for _, def := range descriptor.Tables {
+		tableData := dataReader.ReadTable(def)
+		if tableData == nil {
+			continue
+		}
+		if checkChars {
+			// fixed cells are checked with the fixed value
+			charChecker.CheckTable(tableData)
+		}
+		tableDatas = append(tableDatas, tableData)
+	}
+	if fixChars {
+		charChecker.WriteFixedFiles()
+	}
+	dataChecker.CheckTables(tableDatas)
+	for _, tableData := range tableDatas {
+		tableData.Close()
+	}
+
+	g_diagnostics.Append(dataReader.Diagnostics)
+	g_diagnostics.Append(charChecker.Diagnostics)
+	g_diagnostics.Append(dataChecker.Diagnostics)
+
+	return dataReader.Diagnostics.ErrorCount() == 0 &&
+		charChecker.Diagnostics.ErrorCount() == 0 &&
+		dataChecker.Diagnostics.ErrorCount() == 0
+}
+
+func main() {
+	exitCode := run()
+	if g_diagnostics.Output(g_diagnosticsFormat,
+		"brickred-table-checker") == false {
+		exitCode = 1
+	}
+	os.Exit(exitCode)
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/kaienkira/brickred-table-compiler-v2/compiler/internal"
+	flag "github.com/spf13/pflag"
+)
+
+var g_diagnostics = NewDiagnosticList()
+var g_diagnosticsFormat = DiagnosticFormat_Text
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, ""+
+		"brickred table compat\n"+
+		"usage: %s "+
+		"-o <old_define_file> "+
+		"-n <new_define_file>"+
+		"\n"+
+		"    [-r <reader>] only check the reader\n"+
+		"    [--strict] breaking changes are errors\n"+
+		"    [--diagnostics-format <format>] "+
+		"(text|json|sarif) default is text\n",
+		filepath.Base(os.Args[0]))
+}
+
+func run() int {
+	// parse command line options
+	var optHelp bool
+	var optOldDefineFilePath string
+	var optNewDefineFilePath string
+	var optReader string
+	var optStrict bool
+	var optDiagnosticsFormat string
+
+	flagSet := flag.NewFlagSet("main", flag.ContinueOnError)
+	flagSet.BoolVarP(&optHelp, "help", "h", false, "")
+	flagSet.StringVarP(&optOldDefineFilePath,
+		"-old_define_file_path", "o", "", "")
+	flagSet.StringVarP(&optNewDefineFilePath,
+		"-new_define_file_path", "n", "", "")
+	flagSet.StringVarP(&optReader, "-reader", "r", "", "")
+	flagSet.BoolVar(&optStrict, "strict", false, "")
+	flagSet.StringVar(&optDiagnosticsFormat, "diagnostics-format", "", "")
+
+	if flagSet.Parse(os.Args[1:]) != nil {
+		printUsage()
+		return 1
+	}
+	if optHelp {
+		printUsage()
+		return 0
+	}
+
+	// check command line options
+	// -- required options
+	if optOldDefineFilePath == "" ||
+		optNewDefineFilePath == "" {
+		printUsage()
+		return 1
+	}
+	// -- option default value
+	if optDiagnosticsFormat == "" {
+		optDiagnosticsFormat = "text"
+	}
+
+	// -- check option diagnostics_format
+	g_diagnosticsFormat = ParseDiagnosticFormat(optDiagnosticsFormat)
+	if g_diagnosticsFormat == DiagnosticFormat_None {
+		g_diagnosticsFormat = DiagnosticFormat_Text
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"diagnostics_format `%s` is invalid",
+			optDiagnosticsFormat)
+		return 1
+	}
+
+	// -- check option old_define_file_path
+	if UtilCheckFileExists(optOldDefineFilePath) == false {
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"can not find define file `%s`",
+			optOldDefineFilePath)
+		return 1
+	}
+
+	// -- check option new_define_file_path
+	if UtilCheckFileExists(optNewDefineFilePath) == false {
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"can not find define file `%s`",
+			optNewDefineFilePath)
+		return 1
+	}
+
+	// create parsers
+	oldParser := NewTableParser()
+	defer oldParser.Close()
+	oldParseOk := oldParser.Parse(optOldDefineFilePath)
+	g_diagnostics.Append(oldParser.Diagnostics)
+
+	newParser := NewTableParser()
+	defer newParser.Close()
+	newParseOk := newParser.Parse(optNewDefineFilePath)
+	g_diagnostics.Append(newParser.Diagnostics)
+
+	if oldParseOk == false || newParseOk == false {
+		return 1
+	}
+
+	if checkCompat(oldParser.Descriptor, newParser.Descriptor,
+		optOldDefineFilePath, optNewDefineFilePath,
+		optReader, optStrict) == false {
+		return 1
+	}
+
+	return 0
+}
+
+func checkCompat(
+	oldDescriptor *TableDescriptor, newDescriptor *TableDescriptor,
+	oldDefineFilePath string, newDefineFilePath string,
+	reader string, strict bool) bool {
+
+	checker := NewTableCompatChecker(strict)
+	defer checker.Close()
+
+	var readers []string
+	if reader != "" {
+		if _, ok := oldDescriptor.Readers[reader]; ok == false {
+			g_diagnostics.AddError("undefined-reader", "", 0, 0,
+				"reader `%s` is not defined in old define file",
+				reader)
+			return false
+		}
+		readers = []string{reader}
+	} else {
+		readers = checker.GetReaderNames(oldDescriptor)
+	}
+	checker.CheckReaders(oldDescriptor, newDescriptor, readers)
+
+	checkOk := true
+	if len(readers) == 0 {
+		checker.CheckTables(oldDescriptor, newDescriptor, "")
+	}
+	for _, name := range readers {
+		if _, ok := newDescriptor.Readers[name]; ok == false {
+			continue
+		}
+
+		// filter removes definitions, so parse again for each reader
+		oldParser := parseReaderDefineFile(oldDefineFilePath, name)
+		newParser := parseReaderDefineFile(newDefineFilePath, name)
+		if oldParser != nil && newParser != nil {
+			checker.CheckTables(
+				oldParser.Descriptor, newParser.Descriptor, name)
+		} else {
+			checkOk = false
+		}
+		if oldParser != nil {
+			oldParser.Close()
+		}
+		if newParser != nil {
+			newParser.Close()
+		}
+	}
+
+	g_diagnostics.Append(checker.Diagnostics)
+
+	return checkOk && checker.Diagnostics.ErrorCount() == 0
+}
+
+// the define file is already parsed successfully,
+// so only unexpected failures are reported
+func parseReaderDefineFile(
+	defineFilePath string, reader string) *TableParser {
+
+	parser := NewTableParser()
+	if parser.Parse(defineFilePath) == false ||
+		parser.FilterByReader(reader) == false {
+		g_diagnostics.Append(parser.Diagnostics)
+		parser.Close()
+		return nil
+	}
+
+	return parser
+}
+
+func main() {
+	exitCode := run()
+	if g_diagnostics.Output(g_diagnosticsFormat,
+		"brickred-table-compat") == false {
+		exitCode = 1
+	}
+	os.Exit(exitCode)
+}
@@ -9,6 +9,9 @@ import (
 	flag "github.com/spf13/pflag"
 )
 
+var g_diagnostics = NewDiagnosticList()
+var g_diagnosticsFormat = DiagnosticFormat_Text
+
 func printUsage() {
 	fmt.Fprintf(os.Stderr, ""+
 		"brickred table compiler\n"+
@@ -19,7 +22,9 @@ func printUsage() {
 		"\n"+
 		"    [-o <output_dir>]\n"+
 		"    [-n <new_line_type>] (unix|dos) default is unix\n"+
-		"language supported: cpp csharp\n",
+		"    [--diagnostics-format <format>] "+
+		"(text|json|sarif) default is text\n"+
+		"language supported: cpp csharp proto fbs\n",
 		filepath.Base(os.Args[0]))
 }
 
@@ -31,6 +36,7 @@ func run() int {
 	var optReader string
 	var optOutputDir string
 	var optNewLineType string
+	var optDiagnosticsFormat string
 
 	flagSet := flag.NewFlagSet("main", flag.ContinueOnError)
 	flagSet.BoolVarP(&optHelp, "help", "h", false, "")
@@ -39,6 +45,7 @@ func run() int {
 	flagSet.StringVarP(&optReader, "-reader", "r", "", "")
 	flagSet.StringVarP(&optOutputDir, "-output_dir", "o", "", "")
 	flagSet.StringVarP(&optNewLineType, "-new_line_type", "n", "", "")
+	flagSet.StringVar(&optDiagnosticsFormat, "diagnostics-format", "", "")
 
 	if flagSet.Parse(os.Args[1:]) != nil {
 		printUsage()
@@ -63,28 +70,43 @@ func run() int {
 	if optNewLineType == "" {
 		optNewLineType = "unix"
 	}
+	if optDiagnosticsFormat == "" {
+		optDiagnosticsFormat = "text"
+	}
+
+	// -- check option diagnostics_format
+	g_diagnosticsFormat = ParseDiagnosticFormat(optDiagnosticsFormat)
+	if g_diagnosticsFormat == DiagnosticFormat_None {
+		g_diagnosticsFormat = DiagnosticFormat_Text
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"diagnostics_format `%s` is invalid",
+			optDiagnosticsFormat)
+		return 1
+	}
 
 	// -- check option define_file_path
 	if UtilCheckFileExists(optDefineFilePath) == false {
-		fmt.Fprintf(os.Stderr,
-			"error: can not find define file `%s`\n",
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"can not find define file `%s`",
 			optDefineFilePath)
 		return 1
 	}
 
 	// -- check option language
 	if optLanguage != "cpp" &&
-		optLanguage != "csharp" {
-		fmt.Fprintf(os.Stderr,
-			"error: language `%s` is not supported\n",
+		optLanguage != "csharp" &&
+		optLanguage != "proto" &&
+		optLanguage != "fbs" {
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"language `%s` is not supported",
 			optLanguage)
 		return 1
 	}
 
 	// -- check option output_dir
 	if UtilCheckDirExists(optOutputDir) == false {
-		fmt.Fprintf(os.Stderr,
-			"error: can not find output directory `%s`\n",
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"can not find output directory `%s`",
 			optOutputDir)
 		return 1
 	}
@@ -92,22 +114,22 @@ func run() int {
 	// -- check option new_line_type
 	if optNewLineType != "dos" &&
 		optNewLineType != "unix" {
-		fmt.Fprintf(os.Stderr,
-			"error: new_line_type `%s` is invalid\n",
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"new_line_type `%s` is invalid",
 			optNewLineType)
 		return 1
 	}
 
 	// create parser
 	parser := NewTableParser()
-	if parser.Parse(optDefineFilePath) == false {
-		return 1
-	}
 	defer parser.Close()
-	if optReader != "" {
-		if parser.FilterByReader(optReader) == false {
-			return 1
-		}
+	parseOk := parser.Parse(optDefineFilePath)
+	if parseOk && optReader != "" {
+		parseOk = parser.FilterByReader(optReader)
+	}
+	g_diagnostics.Append(parser.Diagnostics)
+	if parseOk == false {
+		return 1
 	}
 
 	// create generator
@@ -116,6 +138,10 @@ func run() int {
 		generator = NewCppCodeGenerator()
 	} else if optLanguage == "csharp" {
 		generator = NewCSharpCodeGenerator()
+	} else if optLanguage == "proto" {
+		generator = NewProtoCodeGenerator()
+	} else if optLanguage == "fbs" {
+		generator = NewFlatBuffersCodeGenerator()
 	} else {
 		return 1
 	}
@@ -126,8 +152,10 @@ func run() int {
 	if optNewLineType == "dos" {
 		newLineType = NewLineType_Dos
 	}
-	if generator.Generate(parser.Descriptor,
-		optReader, optOutputDir, newLineType) == false {
+	generateOk := generator.Generate(parser.Descriptor,
+		optReader, optOutputDir, newLineType)
+	g_diagnostics.Append(generator.GetDiagnostics())
+	if generateOk == false {
 		return 1
 	}
 
@@ -135,5 +163,10 @@ func run() int {
 }
 
 func main() {
-	os.Exit(run())
+	exitCode := run()
+	if g_diagnostics.Output(g_diagnosticsFormat,
+		"brickred-table-compiler") == false {
+		exitCode = 1
+	}
+	os.Exit(exitCode)
 }
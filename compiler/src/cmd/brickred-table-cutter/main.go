@@ -4,12 +4,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	. "github.com/kaienkira/brickred-table-compiler-v2/compiler/internal"
 	flag "github.com/spf13/pflag"
 )
 
+var g_diagnostics = NewDiagnosticList()
+var g_diagnosticsFormat = DiagnosticFormat_Text
+
 func printUsage() {
 	fmt.Fprintf(os.Stderr, ""+
 		"brickred table cutter\n"+
@@ -17,7 +21,14 @@ func printUsage() {
 		"-f <define_file> "+
 		"-r <reader> "+
 		"-i <input_dir> "+
-		"-o <output_dir>\n",
+		"-o <output_dir>"+
+		"\n"+
+		"    [--check-chars] report full-width and invisible characters\n"+
+		"    [--fix-chars] normalize them in output files\n"+
+		"    [--manifest] write manifest.json of output files\n"+
+		"    [--build-version <version>] build version in manifest\n"+
+		"    [--diagnostics-format <format>] "+
+		"(text|json|sarif) default is text\n",
 		filepath.Base(os.Args[0]))
 }
 
@@ -28,6 +39,11 @@ func run() int {
 	var optReader string
 	var optInputDir string
 	var optOutputDir string
+	var optCheckChars bool
+	var optFixChars bool
+	var optManifest bool
+	var optBuildVersion string
+	var optDiagnosticsFormat string
 
 	flagSet := flag.NewFlagSet("main", flag.ContinueOnError)
 	flagSet.BoolVarP(&optHelp, "help", "h", false, "")
@@ -35,6 +51,11 @@ func run() int {
 	flagSet.StringVarP(&optReader, "-reader", "r", "", "")
 	flagSet.StringVarP(&optInputDir, "-input_dir", "i", "", "")
 	flagSet.StringVarP(&optOutputDir, "-output_dir", "o", "", "")
+	flagSet.BoolVar(&optCheckChars, "check-chars", false, "")
+	flagSet.BoolVar(&optFixChars, "fix-chars", false, "")
+	flagSet.BoolVar(&optManifest, "manifest", false, "")
+	flagSet.StringVar(&optBuildVersion, "build-version", "", "")
+	flagSet.StringVar(&optDiagnosticsFormat, "diagnostics-format", "", "")
 
 	if flagSet.Parse(os.Args[1:]) != nil {
 		printUsage()
@@ -54,63 +75,120 @@ func run() int {
 		printUsage()
 		return 1
 	}
+	// -- option default value
+	if optDiagnosticsFormat == "" {
+		optDiagnosticsFormat = "text"
+	}
+
+	// -- check option diagnostics_format
+	g_diagnosticsFormat = ParseDiagnosticFormat(optDiagnosticsFormat)
+	if g_diagnosticsFormat == DiagnosticFormat_None {
+		g_diagnosticsFormat = DiagnosticFormat_Text
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"diagnostics_format `%s` is invalid",
+			optDiagnosticsFormat)
+		return 1
+	}
 
 	// -- check option define_file_path
 	if UtilCheckFileExists(optDefineFilePath) == false {
-		fmt.Fprintf(os.Stderr,
-			"error: can not find define file `%s`\n",
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"can not find define file `%s`",
 			optDefineFilePath)
 		return 1
 	}
 
 	// -- check option input_dir
 	if UtilCheckDirExists(optInputDir) == false {
-		fmt.Fprintf(os.Stderr,
-			"error: can not find input directory `%s`\n",
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"can not find input directory `%s`",
 			optInputDir)
 		return 1
 	}
 
 	// -- check option output_dir
 	if UtilCheckDirExists(optOutputDir) == false {
-		fmt.Fprintf(os.Stderr,
-			"error: can not find output directory `%s`\n",
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"can not find output directory `%s`",
 			optOutputDir)
 		return 1
 	}
 	if UtilGetFullPath(optInputDir) ==
 		UtilGetFullPath(optOutputDir) {
-		fmt.Fprintf(os.Stderr,
-			"error: output directory can not be same as input directory\n")
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"output directory can not be same as input directory")
 		return 1
 	}
 
 	// create parser
 	parser := NewTableParser()
-	if parser.Parse(optDefineFilePath) == false {
+	defer parser.Close()
+	parseOk := parser.Parse(optDefineFilePath)
+	g_diagnostics.Append(parser.Diagnostics)
+	if parseOk == false {
 		return 1
 	}
-	defer parser.Close()
+
+	// manifest uses the define filtered by reader for schema fingerprint
+	var manifest *TableDataManifest = nil
+	if optManifest {
+		readerParser := NewTableParser()
+		defer readerParser.Close()
+		parseOk := readerParser.Parse(optDefineFilePath)
+		if parseOk {
+			parseOk = readerParser.FilterByReader(optReader)
+		}
+		g_diagnostics.Append(readerParser.Diagnostics)
+		if parseOk == false {
+			return 1
+		}
+		manifest = NewTableDataManifest(readerParser.Descriptor,
+			optReader, optBuildVersion)
+		defer manifest.Close()
+	}
 
 	if cutTables(parser.Descriptor,
-		optReader, optInputDir, optOutputDir) == false {
+		optReader, optInputDir, optOutputDir,
+		optCheckChars || optFixChars, optFixChars, manifest) == false {
 		return 1
 	}
 
+	// write manifest
+	if manifest != nil {
+		manifestFilePath := filepath.Join(optOutputDir, "manifest.json")
+		if err := UtilWriteAllText(
+			manifestFilePath, manifest.ToJson()); err != nil {
+			g_diagnostics.AddError("write-file-failed",
+				manifestFilePath, 0, 0,
+				"write file failed: %s", err.Error())
+			return 1
+		}
+	}
+
 	return 0
 }
 
 func cutTables(descriptor *TableDescriptor,
-	reader string, inputDir string, outputDir string) bool {
+	reader string, inputDir string, outputDir string,
+	checkChars bool, fixChars bool, manifest *TableDataManifest) bool {
 
 	// check reader
 	if _, ok := descriptor.Readers[reader]; ok == false {
-		fmt.Fprintf(os.Stderr,
-			"error: reader `%s` is not defined\n",
+		g_diagnostics.AddError("undefined-reader", "", 0, 0,
+			"reader `%s` is not defined",
 			reader)
 		return false
 	}
 
+	dataReader := NewTableDataReader(inputDir)
+	defer dataReader.Close()
+	var charChecker *TableDataCharChecker = nil
+	if checkChars {
+		charChecker = NewTableDataCharChecker(fixChars)
+		defer charChecker.Close()
+	}
+
+	cutOk := true
 	for _, def := range descriptor.Tables {
 		needCut := false
 		if len(def.Readers) <= 0 {
@@ -121,23 +199,42 @@ func cutTables(descriptor *TableDescriptor,
 		if needCut == false {
 			continue
 		}
-		if cutTable(def, reader, inputDir, outputDir) == false {
-			return false
+		if cutTable(descriptor, dataReader, charChecker,
+			def, reader, outputDir, manifest) == false {
+			cutOk = false
+			break
 		}
 	}
+	g_diagnostics.Append(dataReader.Diagnostics)
+	if charChecker != nil {
+		g_diagnostics.Append(charChecker.Diagnostics)
+	}
 
-	return true
+	return cutOk
 }
 
-func cutTable(tableDef *TableDef,
-	reader string, inputDir string, outputDir string) bool {
+// charChecker is nil when characters are not checked,
+// manifest is nil when manifest is not written
+func cutTable(descriptor *TableDescriptor,
+	dataReader *TableDataReader, charChecker *TableDataCharChecker,
+	tableDef *TableDef, reader string, outputDir string,
+	manifest *TableDataManifest) bool {
 
 	// calucate deleted columns
 	deletedColumns := make(map[int]bool)
+	rowReadbyColumnIndex := -1
 	for i, def := range tableDef.Columns {
+		if tableDef.TableKind == TableKind_Config {
+			break
+		}
 		if def == tableDef.TableKey {
 			continue
 		}
+		if def == tableDef.RowReadbyColumn {
+			rowReadbyColumnIndex = i
+			deletedColumns[i] = true
+			continue
+		}
 		if len(def.Readers) <= 0 {
 			continue
 		}
@@ -147,60 +244,78 @@ func cutTable(tableDef *TableDef,
 		deletedColumns[i] = true
 	}
 
-	// read input file
-	inputFilePath := filepath.Join(inputDir, tableDef.FileName)
-	inputFileContent, ret := UtilReadAllTextShared(inputFilePath)
-	if ret == false {
+	// read input files
+	errorCount := dataReader.Diagnostics.ErrorCount()
+	tableData := dataReader.ReadTable(tableDef)
+	if dataReader.Diagnostics.ErrorCount() > errorCount {
+		if tableData != nil {
+			tableData.Close()
+		}
 		return false
 	}
+	defer tableData.Close()
 
-	// split lines
-	lines := strings.Split(inputFileContent, "\r\n")
-	if lines[len(lines)-1] != "" {
-		fmt.Fprintf(os.Stderr,
-			"error: input file `%s` file line ending is required\n",
-			tableDef.FileName)
+	// check characters, fixed cells are written to output file
+	if charChecker != nil &&
+		charChecker.CheckTable(tableData) == false {
 		return false
 	}
 
-	lineCount := len(lines) - 1
-	if lineCount < 2 {
-		fmt.Fprintf(os.Stderr,
-			"error: input file `%s` comment line and name line is required\n",
-			tableDef.FileName)
-		return false
-	}
+	lineCols := make([][]string, 0, len(tableData.Rows)+2)
+	lineCols = append(lineCols, tableData.CommentLine.Columns)
+	lineCols = append(lineCols, tableData.NameLine.Columns)
+	keyColumnIndex := tableData.KeyColumnIndex()
+	setKey := ""
+	lastOutputSetKey := ""
+	for _, row := range tableData.Rows {
+		cols := row.Columns
+		if tableDef.TableKeyType == TableKeyType_SetKey &&
+			cols[keyColumnIndex] != "" {
+			setKey = cols[keyColumnIndex]
+		}
 
-	// split columns
-	lineCols := make([][]string, 0)
-	for i := range lineCount {
-		cols := strings.Split(lines[i], "\t")
-		if len(cols) != len(tableDef.Columns) {
-			fmt.Fprintf(os.Stderr, ""+
-				"error: input file `%s` line %d "+
-				"column count %d is invalid, should be %d\n",
-				tableDef.FileName, i+1,
-				len(cols), len(tableDef.Columns))
-			return false
+		// cut config rows
+		if tableDef.TableKind == TableKind_Config {
+			def, ok := tableDef.ColumnNameIndex[cols[0]]
+			if ok && len(def.Readers) > 0 {
+				if _, ok := def.Readers[reader]; ok == false {
+					continue
+				}
+			}
 		}
-		lineCols = append(lineCols, cols)
-	}
 
-	// check name line
-	for i, def := range tableDef.Columns {
-		if lineCols[1][i] != def.Name {
-			fmt.Fprintf(os.Stderr,
-				"error: input file `%s` column %d should be named as `%s`\n",
-				tableDef.FileName, i+1, def.Name)
-			return false
+		// cut rows by row readby column
+		if rowReadbyColumnIndex >= 0 &&
+			cols[rowReadbyColumnIndex] != "" {
+			readers, err := descriptor.ResolveReadby(
+				cols[rowReadbyColumnIndex])
+			if err != nil {
+				g_diagnostics.AddError("invalid-readby",
+					row.FilePath, row.LineNumber, rowReadbyColumnIndex+1,
+					"column `%s` %s",
+					tableDef.RowReadbyColumn.Name, err.Error())
+				return false
+			}
+			if _, ok := readers[reader]; ok == false {
+				continue
+			}
+		}
+
+		// first row of the set is cut, fill the set key
+		if tableDef.TableKeyType == TableKeyType_SetKey &&
+			cols[keyColumnIndex] == "" && setKey != lastOutputSetKey {
+			cols = slices.Clone(cols)
+			cols[keyColumnIndex] = setKey
 		}
+		lastOutputSetKey = setKey
+
+		lineCols = append(lineCols, cols)
 	}
 
 	// cut columns
 	var sb strings.Builder
 	outputCols := make([]string, 0)
-	for i := range lineCount {
-		inputCols := lineCols[i]
+	for _, inputCols := range lineCols {
 		outputCols := outputCols[:0]
 		for j, col := range inputCols {
 			if _, ok := deletedColumns[j]; ok {
@@ -215,13 +330,24 @@ func cutTable(tableDef *TableDef,
 
 	// write output file
 	outputFilePath := filepath.Join(outputDir, tableDef.FileName)
-	if UtilWriteAllText(outputFilePath, outputFileContent) == false {
+	if err := UtilWriteAllText(
+		outputFilePath, outputFileContent); err != nil {
+		g_diagnostics.AddError("write-file-failed",
+			outputFilePath, 0, 0, "write file failed: %s", err.Error())
 		return false
 	}
+	if manifest != nil {
+		manifest.AddFile(tableDef.Name, tableDef.FileName, outputFileContent)
+	}
 
 	return true
 }
 
 func main() {
-	os.Exit(run())
+	exitCode := run()
+	if g_diagnostics.Output(g_diagnosticsFormat,
+		"brickred-table-cutter") == false {
+		exitCode = 1
+	}
+	os.Exit(exitCode)
 }
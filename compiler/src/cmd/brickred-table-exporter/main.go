@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/kaienkira/brickred-table-compiler-v2/compiler/internal"
+	flag "github.com/spf13/pflag"
+)
+
+var g_diagnostics = NewDiagnosticList()
+var g_diagnosticsFormat = DiagnosticFormat_Text
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, ""+
+		"brickred table exporter\n"+
+		"usage: %s "+
+		"-f <define_file> "+
+		"-r <reader> "+
+		"-t <format> "+
+		"-i <cut_data_dir> "+
+		"-o <output_dir>"+
+		"\n"+
+		"    [--schema-hash] add schema hash header to msgpack file\n"+
+		"    [--compress] deflate entries of bundle file\n"+
+		"    [--diagnostics-format <format>] "+
+		"(text|json|sarif) default is text\n"+
+		"format supported: "+
+		"binary json sqlite msgpack bundle lua protobuf flatbuffers\n",
+		filepath.Base(os.Args[0]))
+}
+
+func run() int {
+	// parse command line options
+	var optHelp bool
+	var optDefineFilePath string
+	var optReader string
+	var optFormat string
+	var optInputDir string
+	var optOutputDir string
+	var optSchemaHash bool
+	var optCompress bool
+	var optDiagnosticsFormat string
+
+	flagSet := flag.NewFlagSet("main", flag.ContinueOnError)
+	flagSet.BoolVarP(&optHelp, "help", "h", false, "")
+	flagSet.StringVarP(&optDefineFilePath, "-define_file_path", "f", "", "")
+	flagSet.StringVarP(&optReader, "-reader", "r", "", "")
+	flagSet.StringVarP(&optFormat, "-format", "t", "", "")
+	flagSet.StringVarP(&optInputDir, "-input_dir", "i", "", "")
+	flagSet.StringVarP(&optOutputDir, "-output_dir", "o", "", "")
+	flagSet.BoolVar(&optSchemaHash, "schema-hash", false, "")
+	flagSet.BoolVar(&optCompress, "compress", false, "")
+	flagSet.StringVar(&optDiagnosticsFormat, "diagnostics-format", "", "")
+
+	if flagSet.Parse(os.Args[1:]) != nil {
+		printUsage()
+		return 1
+	}
+	if optHelp {
+		printUsage()
+		return 0
+	}
+
+	// check command line options
+	// -- required options
+	if optDefineFilePath == "" ||
+		optReader == "" ||
+		optFormat == "" ||
+		optInputDir == "" ||
+		optOutputDir == "" {
+		printUsage()
+		return 1
+	}
+	// -- option default value
+	if optDiagnosticsFormat == "" {
+		optDiagnosticsFormat = "text"
+	}
+
+	// -- check option diagnostics_format
+	g_diagnosticsFormat = ParseDiagnosticFormat(optDiagnosticsFormat)
+	if g_diagnosticsFormat == DiagnosticFormat_None {
+		g_diagnosticsFormat = DiagnosticFormat_Text
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"diagnostics_format `%s` is invalid",
+			optDiagnosticsFormat)
+		return 1
+	}
+
+	// -- check option define_file_path
+	if UtilCheckFileExists(optDefineFilePath) == false {
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"can not find define file `%s`",
+			optDefineFilePath)
+		return 1
+	}
+
+	// -- check option format
+	if optFormat != "binary" &&
+		optFormat != "json" &&
+		optFormat != "sqlite" &&
+		optFormat != "msgpack" &&
+		optFormat != "bundle" &&
+		optFormat != "lua" &&
+		optFormat != "protobuf" &&
+		optFormat != "flatbuffers" {
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"format `%s` is not supported",
+			optFormat)
+		return 1
+	}
+
+	// -- check option input_dir
+	if UtilCheckDirExists(optInputDir) == false {
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"can not find input directory `%s`",
+			optInputDir)
+		return 1
+	}
+
+	// -- check option output_dir
+	if UtilCheckDirExists(optOutputDir) == false {
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"can not find output directory `%s`",
+			optOutputDir)
+		return 1
+	}
+
+	// create parser, cut data only has columns of the reader
+	parser := NewTableParser()
+	defer parser.Close()
+	parseOk := parser.Parse(optDefineFilePath)
+	if parseOk {
+		parseOk = parser.FilterByReader(optReader)
+	}
+	g_diagnostics.Append(parser.Diagnostics)
+	if parseOk == false {
+		return 1
+	}
+
+	// create exporter
+	var exporter DataExporter = nil
+	if optFormat == "binary" {
+		exporter = NewBinaryDataExporter()
+	} else if optFormat == "json" {
+		exporter = NewJsonDataExporter()
+	} else if optFormat == "sqlite" {
+		exporter = NewSqliteDataExporter()
+	} else if optFormat == "msgpack" {
+		exporter = NewMsgpackDataExporter(optSchemaHash)
+	} else if optFormat == "bundle" {
+		exporter = NewBundleDataExporter(optCompress)
+	} else if optFormat == "lua" {
+		exporter = NewLuaDataExporter()
+	} else if optFormat == "protobuf" {
+		exporter = NewProtobufDataExporter()
+	} else if optFormat == "flatbuffers" {
+		exporter = NewFlatBuffersDataExporter()
+	} else {
+		return 1
+	}
+	defer exporter.Close()
+
+	if exportTables(parser.Descriptor, exporter,
+		optReader, optInputDir, optOutputDir) == false {
+		return 1
+	}
+
+	return 0
+}
+
+func exportTables(descriptor *TableDescriptor, exporter DataExporter,
+	reader string, inputDir string, outputDir string) bool {
+
+	// read cut data files
+	dataReader := NewTableDataReader(inputDir)
+	defer dataReader.Close()
+
+	tableDatas := make([]*TableData, 0, len(descriptor.Tables))
+	defer func() {
+		for _, tableData := range tableDatas {
+			tableData.Close()
+		}
+	}()
+	for _, def := range descriptor.Tables {
+		tableData := dataReader.ReadCutTable(def)
+		if tableData != nil {
+			tableDatas = append(tableDatas, tableData)
+		}
+	}
+	g_diagnostics.Append(dataReader.Diagnostics)
+	if dataReader.Diagnostics.ErrorCount() > 0 {
+		return false
+	}
+
+	// export data
+	exportOk := exporter.Export(descriptor, reader, tableDatas, outputDir)
+	g_diagnostics.Append(exporter.GetDiagnostics())
+
+	return exportOk
+}
+
+func main() {
+	exitCode := run()
+	if g_diagnostics.Output(g_diagnosticsFormat,
+		"brickred-table-exporter") == false {
+		exitCode = 1
+	}
+	os.Exit(exitCode)
+}
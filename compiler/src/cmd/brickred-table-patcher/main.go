@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/kaienkira/brickred-table-compiler-v2/compiler/internal"
+	flag "github.com/spf13/pflag"
+)
+
+var g_diagnostics = NewDiagnosticList()
+var g_diagnosticsFormat = DiagnosticFormat_Text
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, ""+
+		"brickred table patcher\n"+
+		"usage: %s "+
+		"-f <define_file> "+
+		"-r <reader> "+
+		"--old <old_data> "+
+		"--new <new_data> "+
+		"-p <patch_file>"+
+		"\n"+
+		"    [--apply] apply patch file to old data and write new data\n"+
+		"    [--diagnostics-format <format>] "+
+		"(text|json|sarif) default is text\n"+
+		"data is a cut data directory or a bundle file,\n"+
+		"only table data files of a directory are patched\n",
+		filepath.Base(os.Args[0]))
+}
+
+func run() int {
+	// parse command line options
+	var optHelp bool
+	var optDefineFilePath string
+	var optReader string
+	var optOldDataPath string
+	var optNewDataPath string
+	var optPatchFilePath string
+	var optApply bool
+	var optDiagnosticsFormat string
+
+	flagSet := flag.NewFlagSet("main", flag.ContinueOnError)
+	flagSet.BoolVarP(&optHelp, "help", "h", false, "")
+	flagSet.StringVarP(&optDefineFilePath, "-define_file_path", "f", "", "")
+	flagSet.StringVarP(&optReader, "-reader", "r", "", "")
+	flagSet.StringVar(&optOldDataPath, "old", "", "")
+	flagSet.StringVar(&optNewDataPath, "new", "", "")
+	flagSet.StringVarP(&optPatchFilePath, "-patch_file_path", "p", "", "")
+	flagSet.BoolVar(&optApply, "apply", false, "")
+	flagSet.StringVar(&optDiagnosticsFormat, "diagnostics-format", "", "")
+
+	if flagSet.Parse(os.Args[1:]) != nil {
+		printUsage()
+		return 1
+	}
+	if optHelp {
+		printUsage()
+		return 0
+	}
+
+	// check command line options
+	// -- required options
+	if optDefineFilePath == "" ||
+		optReader == "" ||
+		optOldDataPath == "" ||
+		optNewDataPath == "" ||
+		optPatchFilePath == "" {
+		printUsage()
+		return 1
+	}
+	// -- option default value
+	if optDiagnosticsFormat == "" {
+		optDiagnosticsFormat = "text"
+	}
+
+	// -- check option diagnostics_format
+	g_diagnosticsFormat = ParseDiagnosticFormat(optDiagnosticsFormat)
+	if g_diagnosticsFormat == DiagnosticFormat_None {
+		g_diagnosticsFormat = DiagnosticFormat_Text
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"diagnostics_format `%s` is invalid",
+			optDiagnosticsFormat)
+		return 1
+	}
+
+	// -- check option define_file_path
+	if UtilCheckFileExists(optDefineFilePath) == false {
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"can not find define file `%s`",
+			optDefineFilePath)
+		return 1
+	}
+
+	// -- check option old_data_path
+	isDir := UtilCheckDirExists(optOldDataPath)
+	if isDir == false && UtilCheckFileExists(optOldDataPath) == false {
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"can not find old data `%s`",
+			optOldDataPath)
+		return 1
+	}
+
+	// -- check option new_data_path,
+	// -- new bundle file is created when applying patch
+	if isDir {
+		if UtilCheckDirExists(optNewDataPath) == false {
+			g_diagnostics.AddError("invalid-option", "", 0, 0,
+				"can not find new data directory `%s`",
+				optNewDataPath)
+			return 1
+		}
+	} else if optApply == false {
+		if UtilCheckFileExists(optNewDataPath) == false {
+			g_diagnostics.AddError("invalid-option", "", 0, 0,
+				"can not find new data file `%s`",
+				optNewDataPath)
+			return 1
+		}
+	}
+
+	// -- patch is not applied in place,
+	// -- files of removed tables would be left in the old data
+	if optApply && UtilGetFullPath(optOldDataPath) ==
+		UtilGetFullPath(optNewDataPath) {
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"new data can not be same as old data")
+		return 1
+	}
+
+	// -- check option patch_file_path
+	if optApply && UtilCheckFileExists(optPatchFilePath) == false {
+		g_diagnostics.AddError("invalid-option", "", 0, 0,
+			"can not find patch file `%s`",
+			optPatchFilePath)
+		return 1
+	}
+
+	// create parser, cut data only has columns of the reader
+	parser := NewTableParser()
+	defer parser.Close()
+	parseOk := parser.Parse(optDefineFilePath)
+	if parseOk {
+		parseOk = parser.FilterByReader(optReader)
+	}
+	g_diagnostics.Append(parser.Diagnostics)
+	if parseOk == false {
+		return 1
+	}
+
+	patcher := NewTableDataPatcher(parser.Descriptor, optReader)
+	defer patcher.Close()
+
+	var ok bool
+	if optApply {
+		ok = applyPatch(patcher, isDir,
+			optOldDataPath, optNewDataPath, optPatchFilePath)
+	} else {
+		ok = makePatch(patcher, isDir,
+			optOldDataPath, optNewDataPath, optPatchFilePath)
+	}
+	g_diagnostics.Append(patcher.Diagnostics)
+	if ok == false {
+		return 1
+	}
+
+	return 0
+}
+
+func makePatch(patcher *TableDataPatcher, isDir bool,
+	oldDataPath string, newDataPath string, patchFilePath string) bool {
+
+	var patch *TableDataPatch = nil
+	if isDir {
+		patch = patcher.DiffDirs(oldDataPath, newDataPath)
+	} else {
+		patch = patcher.DiffBundles(oldDataPath, newDataPath)
+	}
+	if patch == nil {
+		return false
+	}
+	defer patch.Close()
+
+	if err := UtilWriteAllText(patchFilePath, patch.ToJson()); err != nil {
+		g_diagnostics.AddError("write-file-failed",
+			patchFilePath, 0, 0,
+			"write file failed: %s", err.Error())
+		return false
+	}
+
+	return true
+}
+
+func applyPatch(patcher *TableDataPatcher, isDir bool,
+	oldDataPath string, newDataPath string, patchFilePath string) bool {
+
+	patchBytes, err := UtilReadAllBytesShared(patchFilePath)
+	if err != nil {
+		g_diagnostics.AddError("read-file-failed", patchFilePath, 0, 0,
+			"read file failed: %s", err.Error())
+		return false
+	}
+	patch, err := ParseTableDataPatch(patchBytes)
+	if err != nil {
+		g_diagnostics.AddError("invalid-patch", patchFilePath, 0, 0,
+			"%s", err.Error())
+		return false
+	}
+	defer patch.Close()
+
+	if isDir {
+		return patcher.ApplyToDir(patch, oldDataPath, newDataPath)
+	} else {
+		return patcher.ApplyToBundle(patch, oldDataPath, newDataPath)
+	}
+}
+
+func main() {
+	exitCode := run()
+	if g_diagnostics.Output(g_diagnosticsFormat,
+		"brickred-table-patcher") == false {
+		exitCode = 1
+	}
+	os.Exit(exitCode)
+}
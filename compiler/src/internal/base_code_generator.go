@@ -6,6 +6,8 @@ import (
 )
 
 type BaseCodeGenerator struct {
+	Diagnostics *DiagnosticList
+
 	descriptor *TableDescriptor
 	reader     string
 	newLineStr string
@@ -25,9 +27,29 @@ func (this *BaseCodeGenerator) init(
 }
 
 func (this *BaseCodeGenerator) close() {
+	if this.Diagnostics != nil {
+		this.Diagnostics.Close()
+		this.Diagnostics = nil
+	}
 	this.descriptor = nil
 }
 
+func (this *BaseCodeGenerator) GetDiagnostics() *DiagnosticList {
+	return this.Diagnostics
+}
+
+func (this *BaseCodeGenerator) writeFile(
+	filePath string, fileContent string) bool {
+
+	if err := UtilWriteAllText(filePath, fileContent); err != nil {
+		this.Diagnostics.AddError("write-file-failed", filePath, 0, 0,
+			"write file failed: %s", err.Error())
+		return false
+	}
+
+	return true
+}
+
 func (this *BaseCodeGenerator) writeLine(
 	sb *strings.Builder, line string) {
 
@@ -0,0 +1,203 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type BaseDataExporter struct {
+	Diagnostics *DiagnosticList
+
+	descriptor *TableDescriptor
+	reader     string
+}
+
+func (this *BaseDataExporter) init(
+	descriptor *TableDescriptor, reader string) {
+
+	this.descriptor = descriptor
+	this.reader = reader
+}
+
+func (this *BaseDataExporter) close() {
+	if this.Diagnostics != nil {
+		this.Diagnostics.Close()
+		this.Diagnostics = nil
+	}
+	this.descriptor = nil
+}
+
+func (this *BaseDataExporter) GetDiagnostics() *DiagnosticList {
+	return this.Diagnostics
+}
+
+func (this *BaseDataExporter) writeFile(
+	filePath string, fileContent string) bool {
+
+	if err := UtilWriteAllText(filePath, fileContent); err != nil {
+		this.Diagnostics.AddError("write-file-failed", filePath, 0, 0,
+			"write file failed: %s", err.Error())
+		return false
+	}
+
+	return true
+}
+
+// table file name with the extension replaced
+func (this *BaseDataExporter) getOutputFileName(
+	tableDef *TableDef, ext string) string {
+
+	return strings.TrimSuffix(tableDef.FileName,
+		filepath.Ext(tableDef.FileName)) + ext
+}
+
+// parse cell values of the table in column define order,
+// config table is returned as one row,
+// empty set key of the rows continuing a set is filled
+func (this *BaseDataExporter) getRowValues(
+	tableData *TableData) ([][]any, bool) {
+
+	tableDef := tableData.TableDefRef
+	if tableDef.TableKind == TableKind_Config {
+		return this.getConfigRowValues(tableData)
+	}
+
+	parseOk := true
+	rowValues := make([][]any, 0, len(tableData.Rows))
+	lastKey := ""
+
+	for _, row := range tableData.Rows {
+		values := make([]any, 0, len(tableDef.Columns))
+
+		for i, def := range tableDef.Columns {
+			text := UnquoteTableCell(row.Columns[i])
+			if def == tableDef.TableKey &&
+				tableDef.TableKeyType == TableKeyType_SetKey {
+				if text == "" {
+					text = lastKey
+				}
+				lastKey = text
+			}
+
+			value, err := ParseTableColumnValue(def, text)
+			if err != nil {
+				this.Diagnostics.AddError("invalid-value", row.FilePath,
+					row.LineNumber, i+1,
+					"column `%s` %s", def.Name, err.Error())
+				parseOk = false
+				continue
+			}
+			values = append(values, value)
+		}
+
+		rowValues = append(rowValues, values)
+	}
+
+	return rowValues, parseOk
+}
+
+func (this *BaseDataExporter) getConfigRowValues(
+	tableData *TableData) ([][]any, bool) {
+
+	tableDef := tableData.TableDefRef
+	parseOk := true
+	found := make(map[*TableColumnDef]any)
+
+	for _, row := range tableData.Rows {
+		name := UnquoteTableCell(row.Columns[0])
+		text := UnquoteTableCell(row.Columns[2])
+
+		def, ok := tableDef.ColumnNameIndex[name]
+		if ok == false {
+			this.Diagnostics.AddError("undefined-config-name", row.FilePath,
+				row.LineNumber, 1,
+				"name `%s` is not defined", name)
+			parseOk = false
+			continue
+		}
+
+		value, err := ParseTableColumnValue(def, text)
+		if err != nil {
+			this.Diagnostics.AddError("invalid-value", row.FilePath,
+				row.LineNumber, 3,
+				"name `%s` %s", name, err.Error())
+			parseOk = false
+			continue
+		}
+		found[def] = value
+	}
+
+	values := make([]any, 0, len(tableDef.Columns))
+	for _, def := range tableDef.Columns {
+		value, ok := found[def]
+		if ok == false {
+			this.Diagnostics.AddError("missing-config-name",
+				tableData.NameLine.FilePath, 0, 0,
+				"name `%s` is missing", def.Name)
+			parseOk = false
+			continue
+		}
+		values = append(values, value)
+	}
+
+	return [][]any{values}, parseOk
+}
+
+// json of the value parsed by ParseTableColumnValue
+func (this *BaseDataExporter) appendJsonColumnValue(
+	buffer []byte, columnDef *TableColumnDef, value any) []byte {
+
+	if columnDef.Type != TableColumnType_List {
+		return this.appendJsonValue(buffer, columnDef.RefStructDef, value)
+	}
+
+	buffer = append(buffer, '[')
+	for i, item := range value.([]any) {
+		if i > 0 {
+			buffer = append(buffer, ',')
+		}
+		buffer = this.appendJsonValue(buffer, columnDef.RefStructDef, item)
+	}
+	buffer = append(buffer, ']')
+
+	return buffer
+}
+
+// structDef is used when value is a struct
+func (this *BaseDataExporter) appendJsonValue(
+	buffer []byte, structDef *StructDef, value any) []byte {
+
+	switch v := value.(type) {
+	case int32:
+		buffer = strconv.AppendInt(buffer, int64(v), 10)
+	case string:
+		buffer = this.appendJsonString(buffer, v)
+	case []any:
+		buffer = append(buffer, '{')
+		for i, def := range structDef.Fields {
+			if i > 0 {
+				buffer = append(buffer, ',')
+			}
+			buffer = this.appendJsonString(buffer, def.Name)
+			buffer = append(buffer, ':')
+			buffer = this.appendJsonValue(buffer, nil, v[i])
+		}
+		buffer = append(buffer, '}')
+	}
+
+	return buffer
+}
+
+func (this *BaseDataExporter) appendJsonString(
+	buffer []byte, s string) []byte {
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+
+	return append(buffer, bytes.TrimSuffix(out.Bytes(), []byte("\n"))...)
+}
@@ -0,0 +1,164 @@
+package lib
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// decode the binary data file of the table to the row values,
+// values are the same as the ones encoded by BinaryDataExporter
+func DecodeTableBinary(tableDef *TableDef, data []byte) ([][]any, error) {
+	decoder := &binaryDataDecoder{data: data}
+
+	if len(data) < 4 || string(data[0:4]) != TableBinaryMagic {
+		return nil, fmt.Errorf("binary magic is invalid")
+	}
+	decoder.pos = 4
+	version, ok := decoder.readUvarint()
+	if ok == false || version != TableBinaryVersion {
+		return nil, fmt.Errorf("binary version is not supported")
+	}
+	schema, ok := decoder.readRawString()
+	if ok == false {
+		return nil, fmt.Errorf("binary schema is truncated")
+	}
+	if schema != GetTableBinarySchema(tableDef) {
+		return nil, fmt.Errorf("binary schema mismatch")
+	}
+
+	// string pool
+	stringCount, ok := decoder.readUvarint()
+	if ok == false || stringCount > uint64(len(data)) {
+		return nil, fmt.Errorf("binary string pool is truncated")
+	}
+	decoder.stringPool = make([]string, 0, stringCount)
+	for range stringCount {
+		s, ok := decoder.readRawString()
+		if ok == false {
+			return nil, fmt.Errorf("binary string pool is truncated")
+		}
+		decoder.stringPool = append(decoder.stringPool, s)
+	}
+
+	// rows
+	rowCount, ok := decoder.readUvarint()
+	if ok == false || rowCount > uint64(len(data)) {
+		return nil, fmt.Errorf("binary rows are truncated")
+	}
+	rowValues := make([][]any, 0, rowCount)
+	for range rowCount {
+		values := make([]any, 0, len(tableDef.Columns))
+		for _, def := range tableDef.Columns {
+			value, ok := decoder.readColumnValue(def)
+			if ok == false {
+				return nil, fmt.Errorf("binary rows are truncated")
+			}
+			values = append(values, value)
+		}
+		rowValues = append(rowValues, values)
+	}
+	if decoder.pos != len(data) {
+		return nil, fmt.Errorf("binary data has trailing bytes")
+	}
+
+	return rowValues, nil
+}
+
+type binaryDataDecoder struct {
+	data       []byte
+	pos        int
+	stringPool []string
+}
+
+func (this *binaryDataDecoder) readColumnValue(
+	columnDef *TableColumnDef) (any, bool) {
+
+	if columnDef.Type != TableColumnType_List {
+		return this.readValue(columnDef.Type, columnDef.RefStructDef)
+	}
+
+	count, ok := this.readUvarint()
+	if ok == false || count > uint64(len(this.data)) {
+		return nil, false
+	}
+	items := make([]any, 0, count)
+	for range count {
+		item, ok := this.readValue(
+			columnDef.ListType, columnDef.RefStructDef)
+		if ok == false {
+			return nil, false
+		}
+		items = append(items, item)
+	}
+
+	return items, true
+}
+
+func (this *binaryDataDecoder) readValue(
+	columnType TableColumnType, structDef *StructDef) (any, bool) {
+
+	if columnType == TableColumnType_Int {
+		return this.readInt()
+	} else if columnType == TableColumnType_String {
+		return this.readString()
+	} else if columnType != TableColumnType_Struct {
+		return nil, false
+	}
+
+	fields := make([]any, 0, len(structDef.Fields))
+	for _, def := range structDef.Fields {
+		var field any
+		var ok bool
+		if def.Type == StructFieldType_Int {
+			field, ok = this.readInt()
+		} else {
+			field, ok = this.readString()
+		}
+		if ok == false {
+			return nil, false
+		}
+		fields = append(fields, field)
+	}
+
+	return fields, true
+}
+
+func (this *binaryDataDecoder) readInt() (any, bool) {
+	v, n := binary.Varint(this.data[this.pos:])
+	if n <= 0 {
+		return nil, false
+	}
+	this.pos += n
+
+	return int32(v), true
+}
+
+func (this *binaryDataDecoder) readString() (any, bool) {
+	index, ok := this.readUvarint()
+	if ok == false || index >= uint64(len(this.stringPool)) {
+		return nil, false
+	}
+
+	return this.stringPool[index], true
+}
+
+func (this *binaryDataDecoder) readUvarint() (uint64, bool) {
+	v, n := binary.Uvarint(this.data[this.pos:])
+	if n <= 0 {
+		return 0, false
+	}
+	this.pos += n
+
+	return v, true
+}
+
+func (this *binaryDataDecoder) readRawString() (string, bool) {
+	length, ok := this.readUvarint()
+	if ok == false || length > uint64(len(this.data)-this.pos) {
+		return "", false
+	}
+	s := string(this.data[this.pos : this.pos+int(length)])
+	this.pos += int(length)
+
+	return s, true
+}
@@ -0,0 +1,212 @@
+package lib
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"path/filepath"
+	"strings"
+)
+
+// binary data file layout, integers are varint encoded:
+//   magic `BRTB` and format version
+//   schema text of the table, see GetTableBinarySchema
+//   string pool, string count and each string as length and bytes
+//   row count, then column values of each row in column define order
+//     int    -> zigzag varint
+//     string -> index of string pool
+//     struct -> field values in field define order
+//     list   -> item count and item values
+// config table is written as one row
+
+const TableBinaryMagic = "BRTB"
+const TableBinaryVersion = 1
+
+// key, column names and value layout of the table,
+// generated loader refuses the data file of a different schema
+func GetTableBinarySchema(tableDef *TableDef) string {
+	columnSchemas := make([]string, 0, len(tableDef.Columns))
+	for _, def := range tableDef.Columns {
+		var schema string
+		if def.Type == TableColumnType_List {
+			schema = "[" + getTableBinaryValueSchema(
+				def.ListType, def.RefStructDef) + "]"
+		} else {
+			schema = getTableBinaryValueSchema(def.Type, def.RefStructDef)
+		}
+		columnSchemas = append(columnSchemas, def.Name+":"+schema)
+	}
+
+	kindName := "normal"
+	if tableDef.TableKind == TableKind_Config {
+		kindName = "config"
+	}
+
+	// key column decides how the generated loader indexes the rows
+	keySchema := ""
+	if tableDef.TableKeyType == TableKeyType_SingleKey {
+		keySchema = "key=" + tableDef.TableKey.Name + " "
+	} else if tableDef.TableKeyType == TableKeyType_SetKey {
+		keySchema = "setkey=" + tableDef.TableKey.Name + " "
+	}
+
+	return kindName + " " + keySchema + strings.Join(columnSchemas, ",")
+}
+
+// crc32 of the table schema, changes when the data layout changes
+func GetTableSchemaFingerprint(tableDef *TableDef) uint32 {
+	return crc32.ChecksumIEEE([]byte(GetTableBinarySchema(tableDef)))
+}
+
+func getTableBinaryValueSchema(
+	columnType TableColumnType, structDef *StructDef) string {
+
+	if columnType == TableColumnType_Int {
+		return "int"
+	} else if columnType == TableColumnType_String {
+		return "string"
+	} else if columnType != TableColumnType_Struct {
+		return "none"
+	}
+
+	fieldSchemas := make([]string, 0, len(structDef.Fields))
+	for _, def := range structDef.Fields {
+		if def.Type == StructFieldType_Int {
+			fieldSchemas = append(fieldSchemas, "int")
+		} else {
+			fieldSchemas = append(fieldSchemas, "string")
+		}
+	}
+
+	return "{" + strings.Join(fieldSchemas, ";") + "}"
+}
+
+// ----------------------------------------------------------------------------
+type BinaryDataExporter struct {
+	BaseDataExporter
+
+	// string pool of the table being exported
+	stringPool []string
+	// string -> index of string pool
+	stringPoolIndex map[string]int
+}
+
+func NewBinaryDataExporter() *BinaryDataExporter {
+	newObj := new(BinaryDataExporter)
+	newObj.Diagnostics = NewDiagnosticList()
+
+	return newObj
+}
+
+func (this *BinaryDataExporter) Close() {
+	this.stringPool = nil
+	this.stringPoolIndex = nil
+	this.close()
+}
+
+func (this *BinaryDataExporter) Export(descriptor *TableDescriptor,
+	reader string, tableDatas []*TableData, outputDir string) bool {
+
+	this.init(descriptor, reader)
+
+	exportOk := true
+	for _, tableData := range tableDatas {
+		rowValues, ok := this.getRowValues(tableData)
+		if ok == false {
+			exportOk = false
+			continue
+		}
+
+		tableDef := tableData.TableDefRef
+		filePath := filepath.Join(outputDir,
+			this.getOutputFileName(tableDef, ".bin"))
+		fileContent := this.encodeTable(tableDef, rowValues)
+		if this.writeFile(filePath, fileContent) == false {
+			exportOk = false
+		}
+	}
+
+	return exportOk
+}
+
+func (this *BinaryDataExporter) encodeTable(
+	tableDef *TableDef, rowValues [][]any) string {
+
+	this.stringPool = make([]string, 0)
+	this.stringPoolIndex = make(map[string]int)
+
+	// strings are added to pool when rows are encoded
+	rowBuffer := make([]byte, 0)
+	rowBuffer = binary.AppendUvarint(rowBuffer, uint64(len(rowValues)))
+	for _, values := range rowValues {
+		for i, value := range values {
+			rowBuffer = this.appendColumnValue(
+				rowBuffer, tableDef.Columns[i], value)
+		}
+	}
+
+	buffer := make([]byte, 0, len(rowBuffer)+64)
+	buffer = append(buffer, TableBinaryMagic...)
+	buffer = binary.AppendUvarint(buffer, TableBinaryVersion)
+	buffer = this.appendRawString(buffer, GetTableBinarySchema(tableDef))
+	buffer = binary.AppendUvarint(buffer, uint64(len(this.stringPool)))
+	for _, s := range this.stringPool {
+		buffer = this.appendRawString(buffer, s)
+	}
+	buffer = append(buffer, rowBuffer...)
+
+	return string(buffer)
+}
+
+// value is parsed by ParseTableColumnValue
+func (this *BinaryDataExporter) appendColumnValue(
+	buffer []byte, columnDef *TableColumnDef, value any) []byte {
+
+	if columnDef.Type != TableColumnType_List {
+		return this.appendValue(buffer, value)
+	}
+
+	items := value.([]any)
+	buffer = binary.AppendUvarint(buffer, uint64(len(items)))
+	for _, item := range items {
+		buffer = this.appendValue(buffer, item)
+	}
+
+	return buffer
+}
+
+// struct value is written as its field values
+func (this *BinaryDataExporter) appendValue(
+	buffer []byte, value any) []byte {
+
+	switch v := value.(type) {
+	case int32:
+		buffer = binary.AppendVarint(buffer, int64(v))
+	case string:
+		buffer = binary.AppendUvarint(buffer, uint64(this.addString(v)))
+	case []any:
+		for _, field := range v {
+			buffer = this.appendValue(buffer, field)
+		}
+	}
+
+	return buffer
+}
+
+func (this *BinaryDataExporter) appendRawString(
+	buffer []byte, s string) []byte {
+
+	buffer = binary.AppendUvarint(buffer, uint64(len(s)))
+	return append(buffer, s...)
+}
+
+func (this *BinaryDataExporter) addString(s string) int {
+	if index, ok := this.stringPoolIndex[s]; ok {
+		return index
+	}
+
+	index := len(this.stringPool)
+	this.stringPool = append(this.stringPool, s)
+	this.stringPoolIndex[s] = index
+
+	return index
+}
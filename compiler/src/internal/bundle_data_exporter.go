@@ -0,0 +1,81 @@
+package lib
+
+import (
+	"path/filepath"
+)
+
+// all tables are packed to one bundle file named as the reader,
+// entry of the bundle is the binary data of a table,
+// integers are little-endian uint32 except name length is uint16:
+//   magic `BRTP` and format version
+//   schema fingerprint, crc32 of table schemas joined by new line
+//   entry count, then each entry as
+//     name, flags, offset, size, raw size and crc32 of stored data
+//   entry data, raw deflate compressed when flags has BundleEntryFlag_Deflate
+
+const TableBundleMagic = "BRTP"
+const TableBundleVersion = 1
+const BundleEntryFlag_Deflate = 0x1
+
+type BundleDataExporter struct {
+	BaseDataExporter
+
+	compress       bool
+	binaryExporter *BinaryDataExporter
+}
+
+func NewBundleDataExporter(compress bool) *BundleDataExporter {
+	newObj := new(BundleDataExporter)
+	newObj.Diagnostics = NewDiagnosticList()
+	newObj.compress = compress
+	newObj.binaryExporter = NewBinaryDataExporter()
+
+	return newObj
+}
+
+func (this *BundleDataExporter) Close() {
+	if this.binaryExporter != nil {
+		this.binaryExporter.Close()
+		this.binaryExporter = nil
+	}
+	this.close()
+}
+
+func (this *BundleDataExporter) Export(descriptor *TableDescriptor,
+	reader string, tableDatas []*TableData, outputDir string) bool {
+
+	this.init(descriptor, reader)
+
+	filePath := filepath.Join(outputDir, reader+".bundle")
+	exportOk := true
+	entries := make([]*TableBundleEntry, 0, len(tableDatas))
+
+	for _, tableData := range tableDatas {
+		rowValues, ok := this.getRowValues(tableData)
+		if ok == false {
+			exportOk = false
+			continue
+		}
+
+		tableDef := tableData.TableDefRef
+		data := []byte(this.binaryExporter.encodeTable(tableDef, rowValues))
+		entry, err := NewTableBundleEntry(tableDef.Name, data, this.compress)
+		if err != nil {
+			this.Diagnostics.AddError("export-failed", filePath, 0, 0,
+				"compress table `%s` failed: %s",
+				tableDef.Name, err.Error())
+			exportOk = false
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if exportOk == false {
+		return false
+	}
+
+	bundle := NewTableBundle(GetTableBundleFingerprint(this.descriptor))
+	defer bundle.Close()
+	bundle.Entries = entries
+
+	return this.writeFile(filePath, string(bundle.Encode()))
+}
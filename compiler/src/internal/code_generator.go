@@ -10,6 +10,7 @@ const (
 
 type CodeGenerator interface {
 	Close()
+	GetDiagnostics() *DiagnosticList
 	Generate(descriptor *TableDescriptor,
 		reader string, outputDir string, newLineType NewLineType) bool
 }
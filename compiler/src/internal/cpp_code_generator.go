@@ -13,6 +13,7 @@ type CppCodeGenerator struct {
 
 func NewCppCodeGenerator() *CppCodeGenerator {
 	newObj := new(CppCodeGenerator)
+	newObj.Diagnostics = NewDiagnosticList()
 
 	return newObj
 }
@@ -32,13 +33,13 @@ func (this *CppCodeGenerator) Generate(
 
 		headerFilePath := filepath.Join(outputDir, underscoreName+".h")
 		headerFileContent := this.generateGlobalStructHeaderFile(def)
-		if UtilWriteAllText(headerFilePath, headerFileContent) == false {
+		if this.writeFile(headerFilePath, headerFileContent) == false {
 			return false
 		}
 
 		sourceFilePath := filepath.Join(outputDir, underscoreName+".cc")
 		sourceFileContent := this.generateGlobalStructSourceFile(def)
-		if UtilWriteAllText(sourceFilePath, sourceFileContent) == false {
+		if this.writeFile(sourceFilePath, sourceFileContent) == false {
 			return false
 		}
 	}
@@ -48,17 +49,23 @@ func (this *CppCodeGenerator) Generate(
 
 		headerFilePath := filepath.Join(outputDir, underscoreName+".h")
 		headerFileContent := this.generateTableHeaderFile(def)
-		if UtilWriteAllText(headerFilePath, headerFileContent) == false {
+		if this.writeFile(headerFilePath, headerFileContent) == false {
 			return false
 		}
 
 		sourceFilePath := filepath.Join(outputDir, underscoreName+".cc")
 		sourceFileContent := this.generateTableSourceFile(def)
-		if UtilWriteAllText(sourceFilePath, sourceFileContent) == false {
+		if this.writeFile(sourceFilePath, sourceFileContent) == false {
 			return false
 		}
 	}
 
+	headerFilePath := filepath.Join(outputDir, "table_bundle.h")
+	headerFileContent := this.generateBundleHeaderFile()
+	if this.writeFile(headerFilePath, headerFileContent) == false {
+		return false
+	}
+
 	return true
 }
 
@@ -109,6 +116,7 @@ func (this *CppCodeGenerator) generateGlobalStructHeaderFile(
 	this.writeDontEditComment(&sb)
 	this.writeGlobalStructHeaderFileIncludeGuardStart(&sb, structDef)
 	this.writeGlobalStructHeaderFileIncludeFileDecl(&sb, structDef)
+	this.writeRuntimeClassForwardDecl(&sb)
 	this.writeNamespaceDeclStart(&sb)
 	this.writeHeaderFileOneStructDecl(&sb, structDef)
 	this.writeNamespaceDeclEnd(&sb)
@@ -139,6 +147,7 @@ func (this *CppCodeGenerator) generateTableHeaderFile(
 	this.writeDontEditComment(&sb)
 	this.writeTableHeaderFileIncludeGuardStart(&sb, tableDef)
 	this.writeTableHeaderFileIncludeFileDecl(&sb, tableDef)
+	this.writeRuntimeClassForwardDecl(&sb)
 	this.writeNamespaceDeclStart(&sb)
 	this.writeTableHeaderFileTableDecl(&sb, tableDef)
 	this.writeNamespaceDeclEnd(&sb)
@@ -161,6 +170,34 @@ func (this *CppCodeGenerator) generateTableSourceFile(
 	return sb.String()
 }
 
+// schema fingerprint of the bundle exported for the reader
+func (this *CppCodeGenerator) generateBundleHeaderFile() string {
+	var sb strings.Builder
+
+	this.writeDontEditComment(&sb)
+	this.writeBundleHeaderFileIncludeGuardStart(&sb)
+	this.writeEmptyLine(&sb)
+	this.writeLine(&sb,
+		"#include <cstdint>")
+	this.writeNamespaceDeclStart(&sb)
+	this.writeEmptyLine(&sb)
+	this.writeLine(&sb,
+		"class TableBundle final {")
+	this.writeLine(&sb,
+		"public:")
+	this.writeLine(&sb,
+		"    // pass to BundleReader::open to refuse bundle of other schema")
+	this.writeLineFormat(&sb,
+		"    static uint32_t getSchemaFingerprint() { return 0x%08xu; }",
+		GetTableBundleFingerprint(this.descriptor))
+	this.writeLine(&sb,
+		"};")
+	this.writeNamespaceDeclEnd(&sb)
+	this.writeTableHeaderFileIncludeGuardEnd(&sb)
+
+	return sb.String()
+}
+
 func (this *CppCodeGenerator) writeDontEditComment(
 	sb *strings.Builder) {
 
@@ -174,6 +211,41 @@ func (this *CppCodeGenerator) writeDontEditComment(
 		" */")
 }
 
+func (this *CppCodeGenerator) writeDescriptionComment(
+	sb *strings.Builder, indent string, description string) {
+
+	if description == "" {
+		return
+	}
+
+	for line := range strings.SplitSeq(description, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			this.writeLineFormat(sb,
+				"%s//",
+				indent)
+		} else {
+			this.writeLineFormat(sb,
+				"%s// %s",
+				indent, line)
+		}
+	}
+}
+
+func (this *CppCodeGenerator) writeRuntimeClassForwardDecl(
+	sb *strings.Builder) {
+
+	this.writeEmptyLine(sb)
+	this.writeLine(sb,
+		"namespace brickred::table {")
+	this.writeLine(sb,
+		"class BinaryReader;")
+	this.writeLine(sb,
+		"class BundleReader;")
+	this.writeLine(sb,
+		"} // namespace brickred::table")
+}
+
 func (this *CppCodeGenerator) writeNamespaceDeclStart(
 	sb *strings.Builder) {
 
@@ -217,6 +289,7 @@ func (this *CppCodeGenerator) writeHeaderFileOneStructDecl(
 	if structDef.ParentRef == nil {
 		this.writeEmptyLine(sb)
 	}
+	this.writeDescriptionComment(sb, indent, structDef.Description)
 	this.writeLineFormat(sb,
 		"%sclass %s {",
 		indent, structDef.Name)
@@ -233,6 +306,9 @@ func (this *CppCodeGenerator) writeHeaderFileOneStructDecl(
 	this.writeLineFormat(sb,
 		"%s    bool parse(const std::string &text);",
 		indent)
+	this.writeLineFormat(sb,
+		"%s    bool parseBinary(brickred::table::BinaryReader *r);",
+		indent)
 
 	if len(structDef.Fields) > 0 {
 		this.writeEmptyLine(sb)
@@ -242,6 +318,8 @@ func (this *CppCodeGenerator) writeHeaderFileOneStructDecl(
 
 		for _, def := range structDef.Fields {
 			cppType := this.getStructFieldCppType(def)
+			this.writeDescriptionComment(
+				sb, indent+"    ", def.Description)
 			this.writeLineFormat(sb,
 				"%s    %s %s;",
 				indent, cppType, def.Name)
@@ -259,6 +337,7 @@ func (this *CppCodeGenerator) writeSourceFileOneStructImpl(
 	this.writeSourceFileOneStructImplConstructor(sb, structDef)
 	this.writeSourceFileOneStructImplDestructor(sb, structDef)
 	this.writeSourceFileOneStructImplParseFunc(sb, structDef)
+	this.writeSourceFileOneStructImplParseBinaryFunc(sb, structDef)
 }
 
 func (this *CppCodeGenerator) writeSourceFileOneStructImplConstructor(
@@ -393,6 +472,46 @@ func (this *CppCodeGenerator) writeSourceFileOneStructImplParseFunc(
 		"}")
 }
 
+func (this *CppCodeGenerator) writeSourceFileOneStructImplParseBinaryFunc(
+	sb *strings.Builder, structDef *StructDef) {
+
+	parentClassPrefix := ""
+	if structDef.ParentRef != nil {
+		parentClassPrefix = fmt.Sprintf("%s::", structDef.ParentRef.Name)
+	}
+
+	this.writeEmptyLine(sb)
+	this.writeLineFormat(sb,
+		"bool %s%s::parseBinary(brickred::table::BinaryReader *r)",
+		parentClassPrefix, structDef.Name)
+	this.writeLine(sb,
+		"{")
+
+	for _, def := range structDef.Fields {
+		if def.Type == StructFieldType_Int {
+			this.writeLineFormat(sb,
+				"    if (r->readInt(&this->%s) == false) {",
+				def.Name)
+		} else if def.Type == StructFieldType_String {
+			this.writeLineFormat(sb,
+				"    if (r->readString(&this->%s) == false) {",
+				def.Name)
+		}
+		this.writeLine(sb,
+			"        return false;")
+		this.writeLine(sb,
+			"    }")
+	}
+	if len(structDef.Fields) > 0 {
+		this.writeEmptyLine(sb)
+	}
+
+	this.writeLine(sb,
+		"    return true;")
+	this.writeLine(sb,
+		"}")
+}
+
 func (this *CppCodeGenerator) writeGlobalStructHeaderFileIncludeGuardStart(
 	sb *strings.Builder, structDef *StructDef) {
 
@@ -451,6 +570,8 @@ func (this *CppCodeGenerator) writeGlobalStructSourceFileIncludeFileDecl(
 		"#include \"%s.h\"",
 		UtilCamelToUnderscore(structDef.Name))
 	this.writeEmptyLine(sb)
+	this.writeLine(sb,
+		"#include <brickred/table/binary_reader.h>")
 	this.writeLine(sb,
 		"#include <brickred/table/column_spliter.h>")
 }
@@ -478,6 +599,26 @@ func (this *CppCodeGenerator) writeTableHeaderFileIncludeGuardStart(
 		guardName)
 }
 
+func (this *CppCodeGenerator) writeBundleHeaderFileIncludeGuardStart(
+	sb *strings.Builder) {
+
+	guardNameParts := make([]string, 0)
+	guardNameParts = append(guardNameParts, "BRICKRED_TABLE_GENERATED")
+	readerDef, ok := this.descriptor.Readers[this.reader]
+	if ok {
+		guardNameParts = append(
+			guardNameParts, readerDef.NamespaceParts...)
+	}
+	guardNameParts = append(guardNameParts, "TABLE_BUNDLE_H")
+	guardName := strings.ToUpper(strings.Join(guardNameParts, "_"))
+
+	this.writeLineFormat(sb,
+		"#ifndef %s",
+		guardName)
+	this.writeLineFormat(sb, "#define %s",
+		guardName)
+}
+
 func (this *CppCodeGenerator) writeTableHeaderFileIncludeGuardEnd(
 	sb *strings.Builder) {
 
@@ -489,10 +630,14 @@ func (this *CppCodeGenerator) writeTableHeaderFileIncludeGuardEnd(
 func (this *CppCodeGenerator) writeTableHeaderFileIncludeFileDecl(
 	sb *strings.Builder, tableDef *TableDef) {
 
-	useCStdIntH := false
+	useVectorH := tableDef.TableKind != TableKind_Config
 	refStructDefs := make([]*StructDef, 0)
 
 	for _, columnDef := range tableDef.Columns {
+		if columnDef.Type == TableColumnType_List {
+			useVectorH = true
+		}
+
 		var checkType TableColumnType
 		if columnDef.Type == TableColumnType_List {
 			checkType = columnDef.ListType
@@ -500,9 +645,7 @@ func (this *CppCodeGenerator) writeTableHeaderFileIncludeFileDecl(
 			checkType = columnDef.Type
 		}
 
-		if checkType == TableColumnType_Int {
-			useCStdIntH = true
-		} else if checkType == TableColumnType_Struct {
+		if checkType == TableColumnType_Struct {
 			def := columnDef.RefStructDef
 			if def == nil {
 				continue
@@ -517,27 +660,23 @@ func (this *CppCodeGenerator) writeTableHeaderFileIncludeFileDecl(
 		}
 	}
 
-	for _, structDef := range tableDef.LocalStructs {
-		for _, def := range structDef.Fields {
-			if def.Type == StructFieldType_Int {
-				useCStdIntH = true
-			}
-		}
-	}
-
 	this.writeEmptyLine(sb)
-	this.writeLine(sb,
-		"#include <cstddef>")
-	if useCStdIntH {
+	if tableDef.TableKind != TableKind_Config {
 		this.writeLine(sb,
-			"#include <cstdint>")
+			"#include <cstddef>")
 	}
 	this.writeLine(sb,
-		"#include <string>")
-	this.writeLine(sb,
-		"#include <unordered_map>")
+		"#include <cstdint>")
 	this.writeLine(sb,
-		"#include <vector>")
+		"#include <string>")
+	if tableDef.TableKind != TableKind_Config {
+		this.writeLine(sb,
+			"#include <unordered_map>")
+	}
+	if useVectorH {
+		this.writeLine(sb,
+			"#include <vector>")
+	}
 
 	if len(refStructDefs) > 0 {
 		this.writeEmptyLine(sb)
@@ -553,6 +692,7 @@ func (this *CppCodeGenerator) writeTableHeaderFileTableDecl(
 	sb *strings.Builder, tableDef *TableDef) {
 
 	this.writeEmptyLine(sb)
+	this.writeDescriptionComment(sb, "", tableDef.Description)
 	this.writeLineFormat(sb,
 		"class %s {",
 		tableDef.Name)
@@ -563,11 +703,17 @@ func (this *CppCodeGenerator) writeTableHeaderFileTableDecl(
 		this.writeHeaderFileOneStructDecl(sb, def)
 		this.writeEmptyLine(sb)
 	}
-	this.writeTableHeaderFileTableDeclRowClassDecl(sb, tableDef)
-	this.writeEmptyLine(sb)
-	this.writeTableHeaderFileTableDeclFuncDecl(sb, tableDef)
-	this.writeEmptyLine(sb)
-	this.writeTableHeaderFileTableDeclMemberDecl(sb, tableDef)
+	if tableDef.TableKind == TableKind_Config {
+		this.writeTableHeaderFileTableDeclFuncDecl(sb, tableDef)
+		this.writeEmptyLine(sb)
+		this.writeTableHeaderFileTableDeclConfigMemberDecl(sb, tableDef)
+	} else {
+		this.writeTableHeaderFileTableDeclRowClassDecl(sb, tableDef)
+		this.writeEmptyLine(sb)
+		this.writeTableHeaderFileTableDeclFuncDecl(sb, tableDef)
+		this.writeEmptyLine(sb)
+		this.writeTableHeaderFileTableDeclMemberDecl(sb, tableDef)
+	}
 
 	this.writeLine(sb,
 		"};")
@@ -590,6 +736,7 @@ func (this *CppCodeGenerator) writeTableHeaderFileTableDeclRowClassDecl(
 
 	for _, def := range tableDef.Columns {
 		cppType := this.getTableColumnCppType(def)
+		this.writeDescriptionComment(sb, "        ", def.Description)
 		this.writeLineFormat(sb,
 			"        %s %s;",
 			cppType, def.Name)
@@ -629,6 +776,20 @@ func (this *CppCodeGenerator) writeTableHeaderFileTableDeclFuncDecl(
 	this.writeEmptyLine(sb)
 	this.writeLine(sb,
 		"    bool parse(const std::string &text, std::string *error_info);")
+	this.writeLine(sb, ""+
+		"    bool parseBinary(const std::string &data, "+
+		"std::string *error_info);")
+	this.writeLine(sb, ""+
+		"    bool parseBundle(const brickred::table::BundleReader &bundle, "+
+		"std::string *error_info);")
+	this.writeEmptyLine(sb)
+	this.writeLineFormat(sb,
+		"    static uint32_t getSchemaFingerprint() { return 0x%08xu; }",
+		GetTableSchemaFingerprint(tableDef))
+
+	if tableDef.TableKind == TableKind_Config {
+		return
+	}
 
 	cppType := this.getTableColumnCppType(tableDef.TableKey)
 	if tableDef.TableKey.Type == TableColumnType_String {
@@ -671,6 +832,21 @@ func (this *CppCodeGenerator) writeTableHeaderFileTableDeclMemberDecl(
 	}
 }
 
+func (this *CppCodeGenerator) writeTableHeaderFileTableDeclConfigMemberDecl(
+	sb *strings.Builder, tableDef *TableDef) {
+
+	this.writeLine(sb,
+		"public:")
+
+	for _, def := range tableDef.Columns {
+		cppType := this.getTableColumnCppType(def)
+		this.writeDescriptionComment(sb, "    ", def.Description)
+		this.writeLineFormat(sb,
+			"    %s %s;",
+			cppType, def.Name)
+	}
+}
+
 func (this *CppCodeGenerator) writeTableSourceFileIncludeFileDecl(
 	sb *strings.Builder, tableDef *TableDef) {
 
@@ -697,6 +873,10 @@ func (this *CppCodeGenerator) writeTableSourceFileIncludeFileDecl(
 	}
 
 	this.writeEmptyLine(sb)
+	this.writeLine(sb,
+		"#include <brickred/table/binary_reader.h>")
+	this.writeLine(sb,
+		"#include <brickred/table/bundle_reader.h>")
 	if useBrickredTableColumnSpliterH {
 		this.writeLine(sb,
 			"#include <brickred/table/column_spliter.h>")
@@ -713,11 +893,21 @@ func (this *CppCodeGenerator) writeTableSourceFileTableImpl(
 	for _, def := range tableDef.LocalStructs {
 		this.writeSourceFileOneStructImpl(sb, def)
 	}
+	if tableDef.TableKind == TableKind_Config {
+		this.writeTableSourceFileTableImplConfigConstructor(sb, tableDef)
+		this.writeTableSourceFileTableImplDestructor(sb, tableDef)
+		this.writeTableSourceFileTableImplParseFunc(sb, tableDef)
+		this.writeTableSourceFileTableImplParseBinaryFunc(sb, tableDef)
+		this.writeTableSourceFileTableImplParseBundleFunc(sb, tableDef)
+		return
+	}
 	this.writeTableSourceFileTableImplRowConstructor(sb, tableDef)
 	this.writeTableSourceFileTableImplRowDestructor(sb, tableDef)
 	this.writeTableSourceFileTableImplConstructor(sb, tableDef)
 	this.writeTableSourceFileTableImplDestructor(sb, tableDef)
 	this.writeTableSourceFileTableImplParseFunc(sb, tableDef)
+	this.writeTableSourceFileTableImplParseBinaryFunc(sb, tableDef)
+	this.writeTableSourceFileTableImplParseBundleFunc(sb, tableDef)
 	if tableDef.TableKeyType == TableKeyType_SingleKey {
 		this.writeTableSourceFileTableImplGetRowFunc(sb, tableDef)
 	} else if tableDef.TableKeyType == TableKeyType_SetKey {
@@ -728,6 +918,20 @@ func (this *CppCodeGenerator) writeTableSourceFileTableImpl(
 func (this *CppCodeGenerator) writeTableSourceFileTableImplRowConstructor(
 	sb *strings.Builder, tableDef *TableDef) {
 
+	this.writeTableSourceFileTableImplColumnsConstructor(sb, tableDef,
+		fmt.Sprintf("%s::Row::Row()", tableDef.Name))
+}
+
+func (this *CppCodeGenerator) writeTableSourceFileTableImplConfigConstructor(
+	sb *strings.Builder, tableDef *TableDef) {
+
+	this.writeTableSourceFileTableImplColumnsConstructor(sb, tableDef,
+		fmt.Sprintf("%s::%s()", tableDef.Name, tableDef.Name))
+}
+
+func (this *CppCodeGenerator) writeTableSourceFileTableImplColumnsConstructor(
+	sb *strings.Builder, tableDef *TableDef, funcName string) {
+
 	hasInitList := false
 	lastInitListFieldIndex := -1
 
@@ -741,12 +945,11 @@ func (this *CppCodeGenerator) writeTableSourceFileTableImplRowConstructor(
 	this.writeEmptyLine(sb)
 	if hasInitList {
 		this.writeLineFormat(sb,
-			"%s::Row::Row() :",
-			tableDef.Name)
+			"%s :",
+			funcName)
 	} else {
-		this.writeLineFormat(sb,
-			"%s::Row::Row()",
-			tableDef.Name)
+		this.writeLine(sb,
+			funcName)
 	}
 
 	if hasInitList {
@@ -830,14 +1033,26 @@ func (this *CppCodeGenerator) writeTableSourceFileTableImplParseFunc(
 	this.writeLine(sb, ""+
 		"    const brickred::table::LineReader::LineBuffer "+
 		"*line_buffer = nullptr;")
+
+	columnNames := make([]string, 0, len(tableDef.Columns))
+	if tableDef.TableKind == TableKind_Config {
+		columnNames = append(columnNames, ConfigTableColumnNames...)
+	} else {
+		for _, def := range tableDef.Columns {
+			columnNames = append(columnNames, def.Name)
+		}
+	}
 	this.writeLineFormat(sb,
 		"    size_t column_count_req = %d;",
-		len(tableDef.Columns))
+		len(columnNames))
 
 	this.writeTableSourceFileTableImplParseFuncReadCommentLine(sb)
-	this.writeTableSourceFileTableImplParseFuncReadNameLine(sb, tableDef)
+	this.writeTableSourceFileTableImplParseFuncReadNameLine(sb, columnNames)
 
-	if tableDef.TableKeyType == TableKeyType_SingleKey {
+	if tableDef.TableKind == TableKind_Config {
+		this.writeTableSourceFileTableImplParseFuncConfigReadDataLine(
+			sb, tableDef)
+	} else if tableDef.TableKeyType == TableKeyType_SingleKey {
 		this.writeTableSourceFileTableImplParseFuncSingleKeyReadDataLine(
 			sb, tableDef)
 	} else if tableDef.TableKeyType == TableKeyType_SetKey {
@@ -886,7 +1101,7 @@ func (this *CppCodeGenerator) writeTableSourceFileTableImplParseFuncReadCommentL
 }
 
 func (this *CppCodeGenerator) writeTableSourceFileTableImplParseFuncReadNameLine(
-	sb *strings.Builder, tableDef *TableDef) {
+	sb *strings.Builder, columnNames []string) {
 
 	this.writeEmptyLine(sb)
 	this.writeLine(sb,
@@ -919,16 +1134,16 @@ func (this *CppCodeGenerator) writeTableSourceFileTableImplParseFuncReadNameLine
 	this.writeLine(sb,
 		"        size_t col_number = 0;")
 	this.writeEmptyLine(sb)
-	for _, def := range tableDef.Columns {
+	for _, columnName := range columnNames {
 		this.writeLineFormat(sb,
 			"        if ((*line_buffer)[col_number++] != \"%s\") {",
-			def.Name)
+			columnName)
 		this.writeLine(sb,
 			"            *error_info = brickred::table::util::error(")
 		this.writeLineFormat(sb, ""+
 			"                \"column %%zd should be named as `%s`\", "+
 			"col_number);",
-			def.Name)
+			columnName)
 		this.writeLine(sb,
 			"            return false;")
 		this.writeLine(sb,
@@ -1155,6 +1370,231 @@ func (this *CppCodeGenerator) writeTableSourceFileTableImplParseFuncSetKeyReadDa
 		"    }")
 }
 
+func (this *CppCodeGenerator) writeTableSourceFileTableImplParseFuncConfigReadDataLine(
+	sb *strings.Builder, tableDef *TableDef) {
+
+	this.writeEmptyLine(sb)
+	this.writeLine(sb,
+		"    // read data lines")
+	this.writeLine(sb,
+		"    size_t line_number = 3;")
+	if len(tableDef.Columns) > 0 {
+		this.writeLineFormat(sb,
+			"    bool found[%d] = {};",
+			len(tableDef.Columns))
+	}
+	this.writeLine(sb,
+		"    for (;;) {")
+	this.writeLine(sb,
+		"        line_buffer = r.nextLine();")
+	this.writeLine(sb,
+		"        if (line_buffer == nullptr) {")
+	this.writeLine(sb,
+		"            break;")
+	this.writeLine(sb,
+		"        }")
+	this.writeLine(sb,
+		"        if (line_buffer->size() != column_count_req) {")
+	this.writeLine(sb,
+		"            *error_info = brickred::table::util::error(")
+	this.writeLine(sb, ""+
+		"                \"line %zd column count %zd is invalid, "+
+		"should be %zd\",")
+	this.writeLine(sb,
+		"                line_number, line_buffer->size(), column_count_req);")
+	this.writeLine(sb,
+		"            return false;")
+	this.writeLine(sb,
+		"        }")
+	this.writeEmptyLine(sb)
+	this.writeLine(sb,
+		"        const std::string &name = (*line_buffer)[0];")
+	if len(tableDef.Columns) > 0 {
+		this.writeLine(sb,
+			"        const std::string &type = (*line_buffer)[1];")
+		this.writeLine(sb,
+			"        const std::string &value = (*line_buffer)[2];")
+		this.writeLine(sb,
+			"        size_t index = 0;")
+		this.writeLine(sb,
+			"        const char *type_req = nullptr;")
+	}
+	this.writeEmptyLine(sb)
+
+	for i, def := range tableDef.Columns {
+		if i == 0 {
+			this.writeLineFormat(sb,
+				"        if (name == \"%s\") {",
+				def.Name)
+		} else {
+			this.writeLineFormat(sb,
+				"        } else if (name == \"%s\") {",
+				def.Name)
+		}
+		this.writeLineFormat(sb,
+			"            index = %d;",
+			i)
+		this.writeLineFormat(sb,
+			"            type_req = \"%s\";",
+			def.TypeName)
+	}
+	if len(tableDef.Columns) > 0 {
+		this.writeLine(sb,
+			"        } else {")
+	} else {
+		this.writeLine(sb,
+			"        {")
+	}
+	this.writeLine(sb,
+		"            *error_info = brickred::table::util::error(")
+	this.writeLine(sb,
+		"                \"line %zd name `%s` is not defined\",")
+	this.writeLine(sb,
+		"                line_number, name.c_str());")
+	this.writeLine(sb,
+		"            return false;")
+	this.writeLine(sb,
+		"        }")
+
+	if len(tableDef.Columns) > 0 {
+		this.writeTableSourceFileTableImplParseFuncConfigCheckValue(sb)
+		this.writeEmptyLine(sb)
+		this.writeTableSourceFileTableImplParseFuncParseConfigValues(
+			sb, tableDef)
+	}
+
+	this.writeEmptyLine(sb)
+	this.writeLine(sb,
+		"        line_number += 1;")
+	this.writeLine(sb,
+		"    }")
+
+	for i, def := range tableDef.Columns {
+		if i == 0 {
+			this.writeEmptyLine(sb)
+		}
+		this.writeLineFormat(sb,
+			"    if (found[%d] == false) {",
+			i)
+		this.writeLineFormat(sb,
+			"        *error_info = \"name `%s` is missing\";",
+			def.Name)
+		this.writeLine(sb,
+			"        return false;")
+		this.writeLine(sb,
+			"    }")
+	}
+}
+
+func (this *CppCodeGenerator) writeTableSourceFileTableImplParseFuncConfigCheckValue(
+	sb *strings.Builder) {
+
+	this.writeLine(sb,
+		"        if (type != type_req) {")
+	this.writeLine(sb,
+		"            *error_info = brickred::table::util::error(")
+	this.writeLine(sb, ""+
+		"                \"line %zd name `%s` type `%s` is invalid, "+
+		"should be `%s`\",")
+	this.writeLine(sb,
+		"                line_number, name.c_str(), type.c_str(), type_req);")
+	this.writeLine(sb,
+		"            return false;")
+	this.writeLine(sb,
+		"        }")
+	this.writeLine(sb,
+		"        if (found[index]) {")
+	this.writeLine(sb,
+		"            *error_info = brickred::table::util::error(")
+	this.writeLine(sb,
+		"                \"line %zd name `%s` is duplicated\",")
+	this.writeLine(sb,
+		"                line_number, name.c_str());")
+	this.writeLine(sb,
+		"            return false;")
+	this.writeLine(sb,
+		"        }")
+	this.writeLine(sb,
+		"        found[index] = true;")
+}
+
+func (this *CppCodeGenerator) writeTableSourceFileTableImplParseFuncParseConfigValues(
+	sb *strings.Builder, tableDef *TableDef) {
+
+	for i, def := range tableDef.Columns {
+		isList := def.Type == TableColumnType_List
+		var checkType TableColumnType
+		if def.Type == TableColumnType_List {
+			checkType = def.ListType
+		} else {
+			checkType = def.Type
+		}
+
+		if i == 0 {
+			this.writeLineFormat(sb,
+				"        if (index == %d) {",
+				i)
+		} else {
+			this.writeLineFormat(sb,
+				"        } else if (index == %d) {",
+				i)
+		}
+
+		if isList {
+			this.writeLineFormat(sb,
+				"            %s.clear();",
+				def.Name)
+		}
+
+		if checkType == TableColumnType_Int {
+			if isList {
+				this.writeLineFormat(sb, ""+
+					"            brickred::table::util::readColumnIntList("+
+					"value, &%s);",
+					def.Name)
+			} else {
+				this.writeLineFormat(sb,
+					"            %s = ::atoi(value.c_str());",
+					def.Name)
+			}
+		} else if checkType == TableColumnType_String {
+			if isList {
+				this.writeLineFormat(sb, ""+
+					"            brickred::table::util::readColumnStringList("+
+					"value, &%s);",
+					def.Name)
+			} else {
+				this.writeLineFormat(sb,
+					"            %s = value;",
+					def.Name)
+			}
+		} else if checkType == TableColumnType_Struct {
+			if isList {
+				this.writeLineFormat(sb, ""+
+					"            if (brickred::table::util::readColumnStructList("+
+					"value, &%s) == false) {",
+					def.Name)
+			} else {
+				this.writeLineFormat(sb,
+					"            if (%s.parse(value) == false) {",
+					def.Name)
+			}
+			this.writeLine(sb,
+				"                *error_info = brickred::table::util::error(")
+			this.writeLineFormat(sb, ""+
+				"                    \"line %%zd name `%s` value is invalid\", "+
+				"line_number);",
+				def.Name)
+			this.writeLine(sb,
+				"                return false;")
+			this.writeLine(sb,
+				"            }")
+		}
+	}
+	this.writeLine(sb,
+		"        }")
+}
+
 func (this *CppCodeGenerator) writeTableSourceFileTableImplParseFuncParseColumns(
 	sb *strings.Builder, tableDef *TableDef) {
 
@@ -1227,6 +1667,276 @@ func (this *CppCodeGenerator) writeTableSourceFileTableImplParseFuncParseColumns
 	}
 }
 
+func (this *CppCodeGenerator) writeTableSourceFileTableImplParseBinaryFunc(
+	sb *strings.Builder, tableDef *TableDef) {
+
+	this.writeEmptyLine(sb)
+	this.writeLineFormat(sb, ""+
+		"bool %s::parseBinary(const std::string &data, "+
+		"std::string *error_info)",
+		tableDef.Name)
+	this.writeLine(sb,
+		"{")
+
+	this.writeLine(sb,
+		"    brickred::table::BinaryReader r(data);")
+	this.writeLine(sb,
+		"    size_t row_count = 0;")
+	this.writeEmptyLine(sb)
+	this.writeLine(sb,
+		"    // read header")
+	this.writeLineFormat(sb,
+		"    if (r.readHeader(\"%s\", error_info) == false) {",
+		GetTableBinarySchema(tableDef))
+	this.writeLine(sb,
+		"        return false;")
+	this.writeLine(sb,
+		"    }")
+	if tableDef.TableKind == TableKind_Config {
+		this.writeLine(sb, ""+
+			"    if (r.readSize(&row_count) == false || "+
+			"row_count != 1) {")
+	} else {
+		this.writeLine(sb,
+			"    if (r.readSize(&row_count) == false) {")
+	}
+	this.writeLine(sb,
+		"        *error_info = \"row count is invalid\";")
+	this.writeLine(sb,
+		"        return false;")
+	this.writeLine(sb,
+		"    }")
+
+	if tableDef.TableKind == TableKind_Config {
+		this.writeTableSourceFileTableImplParseBinaryFuncConfigReadRow(
+			sb, tableDef)
+	} else if tableDef.TableKeyType == TableKeyType_SingleKey {
+		this.writeTableSourceFileTableImplParseBinaryFuncSingleKeyReadRows(
+			sb, tableDef)
+	} else if tableDef.TableKeyType == TableKeyType_SetKey {
+		this.writeTableSourceFileTableImplParseBinaryFuncSetKeyReadRows(
+			sb, tableDef)
+	}
+
+	this.writeEmptyLine(sb)
+	this.writeLine(sb,
+		"    if (r.atEnd() == false) {")
+	this.writeLine(sb,
+		"        *error_info = \"data has trailing bytes\";")
+	this.writeLine(sb,
+		"        return false;")
+	this.writeLine(sb,
+		"    }")
+	this.writeEmptyLine(sb)
+	this.writeLine(sb,
+		"    *error_info = \"\";")
+	this.writeLine(sb,
+		"    return true;")
+	this.writeLine(sb,
+		"}")
+}
+
+func (this *CppCodeGenerator) writeTableSourceFileTableImplParseBundleFunc(
+	sb *strings.Builder, tableDef *TableDef) {
+
+	this.writeEmptyLine(sb)
+	this.writeLineFormat(sb, ""+
+		"bool %s::parseBundle(const brickred::table::BundleReader &bundle, "+
+		"std::string *error_info)",
+		tableDef.Name)
+	this.writeLine(sb,
+		"{")
+	this.writeLine(sb,
+		"    std::string data;")
+	this.writeLineFormat(sb,
+		"    if (bundle.readEntry(\"%s\", &data, error_info) == false) {",
+		tableDef.Name)
+	this.writeLine(sb,
+		"        return false;")
+	this.writeLine(sb,
+		"    }")
+	this.writeEmptyLine(sb)
+	this.writeLine(sb,
+		"    return parseBinary(data, error_info);")
+	this.writeLine(sb,
+		"}")
+}
+
+func (this *CppCodeGenerator) writeTableSourceFileTableImplParseBinaryFuncSingleKeyReadRows(
+	sb *strings.Builder, tableDef *TableDef) {
+
+	keyFormat, keyValue := this.getTableKeyFormat(tableDef)
+
+	this.writeEmptyLine(sb)
+	this.writeLine(sb,
+		"    // read rows")
+	this.writeLine(sb,
+		"    rows_.clear();")
+	this.writeLine(sb,
+		"    row_index_.clear();")
+	this.writeLine(sb,
+		"    for (size_t row_number = 1; row_number <= row_count; ++row_number) {")
+	this.writeLine(sb,
+		"        Row row;")
+	this.writeEmptyLine(sb)
+	this.writeTableSourceFileTableImplParseBinaryFuncReadColumns(sb, tableDef)
+	this.writeEmptyLine(sb)
+	this.writeLineFormat(sb,
+		"        if (getRow(row.%s) != nullptr) {",
+		tableDef.TableKey.Name)
+	this.writeLine(sb,
+		"            *error_info = brickred::table::util::error(")
+	this.writeLineFormat(sb, ""+
+		"                \"row %%zd key `%s` value %s is duplicated\", "+
+		"row_number, %s);",
+		tableDef.TableKey.Name, keyFormat, keyValue)
+	this.writeLine(sb,
+		"            return false;")
+	this.writeLine(sb,
+		"        }")
+	this.writeEmptyLine(sb)
+	this.writeLine(sb,
+		"        rows_.push_back(row);")
+	this.writeLineFormat(sb,
+		"        row_index_.insert(std::make_pair(row.%s, rows_.size() - 1));",
+		tableDef.TableKey.Name)
+	this.writeLine(sb,
+		"    }")
+}
+
+func (this *CppCodeGenerator) writeTableSourceFileTableImplParseBinaryFuncSetKeyReadRows(
+	sb *strings.Builder, tableDef *TableDef) {
+
+	keyFormat, keyValue := this.getTableKeyFormat(tableDef)
+
+	this.writeEmptyLine(sb)
+	this.writeLine(sb,
+		"    // read rows, rows of a set are contiguous")
+	this.writeLine(sb,
+		"    row_sets_.clear();")
+	this.writeLine(sb,
+		"    row_set_index_.clear();")
+	this.writeLine(sb,
+		"    for (size_t row_number = 1; row_number <= row_count; ++row_number) {")
+	this.writeLine(sb,
+		"        Row row;")
+	this.writeEmptyLine(sb)
+	this.writeTableSourceFileTableImplParseBinaryFuncReadColumns(sb, tableDef)
+	this.writeEmptyLine(sb)
+	this.writeLineFormat(sb, ""+
+		"        RowSetIndex::const_iterator iter = "+
+		"row_set_index_.find(row.%s);",
+		tableDef.TableKey.Name)
+	this.writeLine(sb,
+		"        if (iter == row_set_index_.end()) {")
+	this.writeLine(sb,
+		"            RowSet &row_set = row_sets_.emplace_back();")
+	this.writeLine(sb,
+		"            row_set.push_back(row);")
+	this.writeLineFormat(sb, ""+
+		"            row_set_index_.insert("+
+		"std::make_pair(row.%s, row_sets_.size() - 1));",
+		tableDef.TableKey.Name)
+	this.writeLine(sb,
+		"        } else if (iter->second == row_sets_.size() - 1) {")
+	this.writeLine(sb,
+		"            row_sets_.back().push_back(row);")
+	this.writeLine(sb,
+		"        } else {")
+	this.writeLine(sb,
+		"            *error_info = brickred::table::util::error(")
+	this.writeLineFormat(sb,
+		"                \"row %%zd key `%s` value %s is duplicated\",",
+		tableDef.TableKey.Name, keyFormat)
+	this.writeLineFormat(sb,
+		"                row_number, %s);",
+		keyValue)
+	this.writeLine(sb,
+		"            return false;")
+	this.writeLine(sb,
+		"        }")
+	this.writeLine(sb,
+		"    }")
+}
+
+func (this *CppCodeGenerator) writeTableSourceFileTableImplParseBinaryFuncConfigReadRow(
+	sb *strings.Builder, tableDef *TableDef) {
+
+	if len(tableDef.Columns) == 0 {
+		return
+	}
+
+	this.writeEmptyLine(sb)
+	this.writeLine(sb,
+		"    // read values")
+	for _, def := range tableDef.Columns {
+		this.writeLineFormat(sb,
+			"    if (%s == false) {",
+			this.getParseBinaryReadExpr(def, def.Name))
+		this.writeLineFormat(sb,
+			"        *error_info = \"name `%s` value is invalid\";",
+			def.Name)
+		this.writeLine(sb,
+			"        return false;")
+		this.writeLine(sb,
+			"    }")
+	}
+}
+
+func (this *CppCodeGenerator) writeTableSourceFileTableImplParseBinaryFuncReadColumns(
+	sb *strings.Builder, tableDef *TableDef) {
+
+	for _, def := range tableDef.Columns {
+		this.writeLineFormat(sb,
+			"        if (%s == false) {",
+			this.getParseBinaryReadExpr(def, "row."+def.Name))
+		this.writeLine(sb,
+			"            *error_info = brickred::table::util::error(")
+		this.writeLineFormat(sb, ""+
+			"                \"row %%zd column `%s` value is invalid\", "+
+			"row_number);",
+			def.Name)
+		this.writeLine(sb,
+			"            return false;")
+		this.writeLine(sb,
+			"        }")
+	}
+}
+
+// expression reading the column value from BinaryReader `r`
+func (this *CppCodeGenerator) getParseBinaryReadExpr(
+	columnDef *TableColumnDef, target string) string {
+
+	if columnDef.Type == TableColumnType_List {
+		if columnDef.ListType == TableColumnType_Int {
+			return fmt.Sprintf("r.readIntList(&%s)", target)
+		} else if columnDef.ListType == TableColumnType_String {
+			return fmt.Sprintf("r.readStringList(&%s)", target)
+		} else {
+			return fmt.Sprintf("r.readStructList(&%s)", target)
+		}
+	}
+
+	if columnDef.Type == TableColumnType_Int {
+		return fmt.Sprintf("r.readInt(&%s)", target)
+	} else if columnDef.Type == TableColumnType_String {
+		return fmt.Sprintf("r.readString(&%s)", target)
+	} else {
+		return fmt.Sprintf("%s.parseBinary(&r)", target)
+	}
+}
+
+// printf format and argument of the row key value
+func (this *CppCodeGenerator) getTableKeyFormat(
+	tableDef *TableDef) (string, string) {
+
+	if tableDef.TableKey.Type == TableColumnType_String {
+		return "%s", fmt.Sprintf("row.%s.c_str()", tableDef.TableKey.Name)
+	} else {
+		return "%d", fmt.Sprintf("row.%s", tableDef.TableKey.Name)
+	}
+}
+
 func (this *CppCodeGenerator) writeTableSourceFileTableImplGetRowFunc(
 	sb *strings.Builder, tableDef *TableDef) {
 
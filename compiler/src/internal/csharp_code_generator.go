@@ -6,6 +6,7 @@ type CSharpCodeGenerator struct {
 
 func NewCSharpCodeGenerator() *CSharpCodeGenerator {
 	newObj := new(CSharpCodeGenerator)
+	newObj.Diagnostics = NewDiagnosticList()
 
 	return newObj
 }
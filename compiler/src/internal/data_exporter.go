@@ -0,0 +1,8 @@
+package lib
+
+type DataExporter interface {
+	Close()
+	GetDiagnostics() *DiagnosticList
+	Export(descriptor *TableDescriptor,
+		reader string, tableDatas []*TableData, outputDir string) bool
+}
@@ -0,0 +1,359 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type DiagnosticSeverity int
+
+const (
+	DiagnosticSeverity_None DiagnosticSeverity = iota
+	DiagnosticSeverity_Error
+	DiagnosticSeverity_Warning
+)
+
+func (this DiagnosticSeverity) String() string {
+	if this == DiagnosticSeverity_Error {
+		return "error"
+	} else if this == DiagnosticSeverity_Warning {
+		return "warning"
+	} else {
+		return "none"
+	}
+}
+
+// ----------------------------------------------------------------------------
+type DiagnosticFormat int
+
+const (
+	DiagnosticFormat_None DiagnosticFormat = iota
+	DiagnosticFormat_Text
+	DiagnosticFormat_Json
+	DiagnosticFormat_Sarif
+)
+
+// return DiagnosticFormat_None when format name is invalid
+func ParseDiagnosticFormat(formatName string) DiagnosticFormat {
+	if formatName == "text" {
+		return DiagnosticFormat_Text
+	} else if formatName == "json" {
+		return DiagnosticFormat_Json
+	} else if formatName == "sarif" {
+		return DiagnosticFormat_Sarif
+	} else {
+		return DiagnosticFormat_None
+	}
+}
+
+// ----------------------------------------------------------------------------
+type Diagnostic struct {
+	// error or warning
+	Severity DiagnosticSeverity
+	// kebab-case identifier of the problem kind, e.g. `duplicated-key`
+	Code string
+	// file the diagnostic belongs to
+	FilePath string
+	// line number in file, 0 when unknown
+	LineNumber int
+	// column number in line, 0 when unknown
+	ColumnNumber int
+
+	Message string
+}
+
+func NewDiagnostic(severity DiagnosticSeverity, code string,
+	filePath string, lineNumber int, columnNumber int,
+	message string) *Diagnostic {
+
+	newObj := new(Diagnostic)
+	newObj.Severity = severity
+	newObj.Code = code
+	newObj.FilePath = filePath
+	newObj.LineNumber = lineNumber
+	newObj.ColumnNumber = columnNumber
+	newObj.Message = message
+
+	return newObj
+}
+
+func (this *Diagnostic) String() string {
+	var sb strings.Builder
+
+	sb.WriteString(this.Severity.String())
+	sb.WriteString(":")
+	if this.FilePath != "" {
+		sb.WriteString(this.FilePath)
+		if this.LineNumber > 0 {
+			fmt.Fprintf(&sb, ":%d", this.LineNumber)
+			if this.ColumnNumber > 0 {
+				fmt.Fprintf(&sb, ":%d", this.ColumnNumber)
+			}
+		}
+		sb.WriteString(":")
+	}
+	sb.WriteString(" ")
+	sb.WriteString(this.Message)
+
+	return sb.String()
+}
+
+// ----------------------------------------------------------------------------
+type DiagnosticList struct {
+	// in report order
+	Items []*Diagnostic
+}
+
+func NewDiagnosticList() *DiagnosticList {
+	newObj := new(DiagnosticList)
+	newObj.Items = make([]*Diagnostic, 0)
+
+	return newObj
+}
+
+func (this *DiagnosticList) Close() {
+	if this.Items != nil {
+		clear(this.Items)
+		this.Items = nil
+	}
+}
+
+func (this *DiagnosticList) Append(other *DiagnosticList) {
+	this.Items = append(this.Items, other.Items...)
+}
+
+func (this *DiagnosticList) AddError(code string, filePath string,
+	lineNumber int, columnNumber int, format string, args ...any) {
+
+	this.Items = append(this.Items, NewDiagnostic(
+		DiagnosticSeverity_Error, code,
+		filePath, lineNumber, columnNumber,
+		fmt.Sprintf(format, args...)))
+}
+
+func (this *DiagnosticList) AddWarning(code string, filePath string,
+	lineNumber int, columnNumber int, format string, args ...any) {
+
+	this.Items = append(this.Items, NewDiagnostic(
+		DiagnosticSeverity_Warning, code,
+		filePath, lineNumber, columnNumber,
+		fmt.Sprintf(format, args...)))
+}
+
+func (this *DiagnosticList) ErrorCount() int {
+	count := 0
+	for _, item := range this.Items {
+		if item.Severity == DiagnosticSeverity_Error {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (this *DiagnosticList) WarningCount() int {
+	count := 0
+	for _, item := range this.Items {
+		if item.Severity == DiagnosticSeverity_Warning {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (this *DiagnosticList) Print(w io.Writer) {
+	for _, item := range this.Items {
+		fmt.Fprintln(w, item.String())
+	}
+}
+
+// text format is written to stderr,
+// json and sarif format are written to stdout for tools to parse
+func (this *DiagnosticList) Output(
+	format DiagnosticFormat, toolName string) bool {
+
+	if format == DiagnosticFormat_Json {
+		return this.writeJson(os.Stdout)
+	} else if format == DiagnosticFormat_Sarif {
+		return this.writeSarif(os.Stdout, toolName)
+	} else {
+		this.Print(os.Stderr)
+		return true
+	}
+}
+
+func (this *DiagnosticList) writeJson(w io.Writer) bool {
+	type jsonDiagnostic struct {
+		Severity string `json:"severity"`
+		Code     string `json:"code"`
+		File     string `json:"file"`
+		Line     int    `json:"line"`
+		Column   int    `json:"column"`
+		Message  string `json:"message"`
+	}
+	type jsonOutput struct {
+		ErrorCount   int              `json:"error_count"`
+		WarningCount int              `json:"warning_count"`
+		Diagnostics  []jsonDiagnostic `json:"diagnostics"`
+	}
+
+	output := jsonOutput{
+		ErrorCount:   this.ErrorCount(),
+		WarningCount: this.WarningCount(),
+		Diagnostics:  make([]jsonDiagnostic, 0, len(this.Items)),
+	}
+	for _, item := range this.Items {
+		output.Diagnostics = append(output.Diagnostics, jsonDiagnostic{
+			Severity: item.Severity.String(),
+			Code:     item.Code,
+			File:     item.FilePath,
+			Line:     item.LineNumber,
+			Column:   item.ColumnNumber,
+			Message:  item.Message,
+		})
+	}
+
+	return this.writeIndentedJson(w, output)
+}
+
+// sarif 2.1.0 log with one run
+func (this *DiagnosticList) writeSarif(w io.Writer, toolName string) bool {
+	type sarifMessage struct {
+		Text string `json:"text"`
+	}
+	type sarifArtifactLocation struct {
+		Uri string `json:"uri"`
+	}
+	type sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+	}
+	type sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+	type sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	type sarifResult struct {
+		RuleId    string          `json:"ruleId"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations,omitempty"`
+	}
+	type sarifRule struct {
+		Id string `json:"id"`
+	}
+	type sarifDriver struct {
+		Name  string      `json:"name"`
+		Rules []sarifRule `json:"rules"`
+	}
+	type sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	type sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	type sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:  toolName,
+				Rules: make([]sarifRule, 0),
+			},
+		},
+		Results: make([]sarifResult, 0, len(this.Items)),
+	}
+	ruleIds := make(map[string]bool)
+	for _, item := range this.Items {
+		if _, ok := ruleIds[item.Code]; ok == false {
+			ruleIds[item.Code] = true
+			run.Tool.Driver.Rules = append(
+				run.Tool.Driver.Rules, sarifRule{Id: item.Code})
+		}
+
+		result := sarifResult{
+			RuleId:  item.Code,
+			Level:   item.Severity.String(),
+			Message: sarifMessage{Text: item.Message},
+		}
+		if item.FilePath != "" {
+			location := sarifLocation{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{
+						Uri: this.getSarifUri(item.FilePath),
+					},
+				},
+			}
+			if item.LineNumber > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{
+					StartLine:   item.LineNumber,
+					StartColumn: item.ColumnNumber,
+				}
+			}
+			result.Locations = []sarifLocation{location}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	return this.writeIndentedJson(w, sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// relative uri when file is under working directory,
+// otherwise absolute file uri
+func (this *DiagnosticList) getSarifUri(filePath string) string {
+	fullPath := UtilGetFullPath(filePath)
+	if fullPath == "" {
+		return filepath.ToSlash(filePath)
+	}
+
+	if workDir, err := os.Getwd(); err == nil {
+		relPath, err := filepath.Rel(workDir, fullPath)
+		if err == nil &&
+			relPath != ".." &&
+			strings.HasPrefix(relPath, ".."+string(filepath.Separator)) == false {
+			return filepath.ToSlash(relPath)
+		}
+	}
+
+	fileUrl := url.URL{
+		Scheme: "file",
+		Path:   filepath.ToSlash(fullPath),
+	}
+	if strings.HasPrefix(fileUrl.Path, "/") == false {
+		fileUrl.Path = "/" + fileUrl.Path
+	}
+
+	return fileUrl.String()
+}
+
+func (this *DiagnosticList) writeIndentedJson(w io.Writer, v any) bool {
+	jsonBytes, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr,
+			"error: encode diagnostics failed: %s\n", err.Error())
+		return false
+	}
+	jsonBytes = append(jsonBytes, '\n')
+	if _, err := w.Write(jsonBytes); err != nil {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,166 @@
+package lib
+
+import (
+	"encoding/binary"
+)
+
+// flatbuffers is built from back to front like the official builder,
+// so offsets to children always point forward,
+// offset of an object is its distance to the end of the buffer,
+// objects can not be created while a table is being built
+type flatBuffersBuilder struct {
+	// built data is buf[head:]
+	buf      []byte
+	head     int
+	minAlign int
+
+	// table being built
+	objectStart int
+	// field slot -> offset of field value, 0 when field is absent
+	fieldOffsets []int
+}
+
+func newFlatBuffersBuilder() *flatBuffersBuilder {
+	newObj := new(flatBuffersBuilder)
+	newObj.buf = make([]byte, 1024)
+	newObj.head = len(newObj.buf)
+	newObj.minAlign = 1
+
+	return newObj
+}
+
+func (this *flatBuffersBuilder) offset() int {
+	return len(this.buf) - this.head
+}
+
+func (this *flatBuffersBuilder) grow(size int) {
+	if this.head >= size {
+		return
+	}
+
+	newSize := len(this.buf) * 2
+	for newSize-len(this.buf)+this.head < size {
+		newSize *= 2
+	}
+	newBuf := make([]byte, newSize)
+	copy(newBuf[newSize-len(this.buf):], this.buf)
+	this.head += newSize - len(this.buf)
+	this.buf = newBuf
+}
+
+func (this *flatBuffersBuilder) pad(size int) {
+	for range size {
+		this.head -= 1
+		this.buf[this.head] = 0
+	}
+}
+
+// align for writing a value of size after additional bytes are written
+func (this *flatBuffersBuilder) prep(size int, additional int) {
+	if size > this.minAlign {
+		this.minAlign = size
+	}
+	alignSize := (-(this.offset() + additional)) & (size - 1)
+	this.grow(alignSize + size + additional)
+	this.pad(alignSize)
+}
+
+func (this *flatBuffersBuilder) prependUint16(v uint16) {
+	this.prep(2, 0)
+	this.head -= 2
+	binary.LittleEndian.PutUint16(this.buf[this.head:], v)
+}
+
+func (this *flatBuffersBuilder) prependUint32(v uint32) {
+	this.prep(4, 0)
+	this.head -= 4
+	binary.LittleEndian.PutUint32(this.buf[this.head:], v)
+}
+
+// offset is relative to the position of itself
+func (this *flatBuffersBuilder) prependUOffset(off int) {
+	this.prep(4, 0)
+	this.head -= 4
+	binary.LittleEndian.PutUint32(this.buf[this.head:],
+		uint32(this.offset()-off))
+}
+
+func (this *flatBuffersBuilder) createString(s string) int {
+	this.prep(4, len(s)+1)
+	this.pad(1)
+	this.head -= len(s)
+	copy(this.buf[this.head:], s)
+	this.prependUint32(uint32(len(s)))
+
+	return this.offset()
+}
+
+func (this *flatBuffersBuilder) createInt32Vector(values []int32) int {
+	this.prep(4, 4*len(values))
+	for i := len(values) - 1; i >= 0; i-- {
+		this.prependUint32(uint32(values[i]))
+	}
+	this.prependUint32(uint32(len(values)))
+
+	return this.offset()
+}
+
+func (this *flatBuffersBuilder) createOffsetVector(offsets []int) int {
+	this.prep(4, 4*len(offsets))
+	for i := len(offsets) - 1; i >= 0; i-- {
+		this.prependUOffset(offsets[i])
+	}
+	this.prependUint32(uint32(len(offsets)))
+
+	return this.offset()
+}
+
+func (this *flatBuffersBuilder) startObject(fieldCount int) {
+	this.objectStart = this.offset()
+	this.fieldOffsets = make([]int, fieldCount)
+}
+
+func (this *flatBuffersBuilder) addInt32(slot int, v int32) {
+	this.prependUint32(uint32(v))
+	this.fieldOffsets[slot] = this.offset()
+}
+
+func (this *flatBuffersBuilder) addOffset(slot int, off int) {
+	this.prependUOffset(off)
+	this.fieldOffsets[slot] = this.offset()
+}
+
+// vtable is written before the table, vtables are not shared
+func (this *flatBuffersBuilder) endObject() int {
+	this.prependUint32(0)
+	objectOffset := this.offset()
+
+	fieldCount := len(this.fieldOffsets)
+	for fieldCount > 0 && this.fieldOffsets[fieldCount-1] == 0 {
+		fieldCount -= 1
+	}
+	for i := fieldCount - 1; i >= 0; i-- {
+		fieldOffset := 0
+		if this.fieldOffsets[i] != 0 {
+			fieldOffset = objectOffset - this.fieldOffsets[i]
+		}
+		this.prependUint16(uint16(fieldOffset))
+	}
+	this.prependUint16(uint16(objectOffset - this.objectStart))
+	this.prependUint16(uint16((fieldCount + 2) * 2))
+
+	// table starts with signed offset to its vtable
+	vtableOffset := this.offset()
+	binary.LittleEndian.PutUint32(this.buf[len(this.buf)-objectOffset:],
+		uint32(int32(vtableOffset-objectOffset)))
+	this.fieldOffsets = nil
+
+	return objectOffset
+}
+
+func (this *flatBuffersBuilder) finish(rootOffset int) []byte {
+	this.prep(this.minAlign, 4)
+	this.prependUOffset(rootOffset)
+
+	return this.buf[this.head:]
+}
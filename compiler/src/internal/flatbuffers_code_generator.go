@@ -0,0 +1,290 @@
+package lib
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// one schema file for each global struct and table,
+// struct is a flatbuffers table, local struct is prefixed by table name,
+// table is the root type holding rows sorted by the key column:
+//   single key table -> rows:[Row]
+//   set key table    -> row_sets:[RowSet], RowSet holds key and rows
+//   config table     -> row:Row
+// data file of FlatBuffersDataExporter is the root type of the table
+
+type FlatBuffersCodeGenerator struct {
+	BaseCodeGenerator
+}
+
+func NewFlatBuffersCodeGenerator() *FlatBuffersCodeGenerator {
+	newObj := new(FlatBuffersCodeGenerator)
+	newObj.Diagnostics = NewDiagnosticList()
+
+	return newObj
+}
+
+func (this *FlatBuffersCodeGenerator) Close() {
+	this.close()
+}
+
+func (this *FlatBuffersCodeGenerator) Generate(
+	descriptor *TableDescriptor,
+	reader string, outputDir string, newLineType NewLineType) bool {
+
+	this.init(descriptor, reader, newLineType)
+
+	for _, def := range this.descriptor.GlobalStructs {
+		underscoreName := UtilCamelToUnderscore(def.Name)
+
+		filePath := filepath.Join(outputDir, underscoreName+".fbs")
+		fileContent := this.generateGlobalStructFile(def)
+		if this.writeFile(filePath, fileContent) == false {
+			return false
+		}
+	}
+
+	for _, def := range this.descriptor.Tables {
+		underscoreName := UtilCamelToUnderscore(def.Name)
+
+		filePath := filepath.Join(outputDir, underscoreName+".fbs")
+		fileContent := this.generateTableFile(def)
+		if this.writeFile(filePath, fileContent) == false {
+			return false
+		}
+	}
+
+	return true
+}
+
+func GetFlatBuffersStructTypeName(structDef *StructDef) string {
+	if structDef.ParentRef == nil {
+		return structDef.Name
+	}
+
+	return structDef.ParentRef.Name + "_" + structDef.Name
+}
+
+func (this *FlatBuffersCodeGenerator) getStructFieldFbsType(
+	fieldDef *StructFieldDef) string {
+
+	fbsType := ""
+	if fieldDef.Type == StructFieldType_Int {
+		fbsType = "int"
+	} else if fieldDef.Type == StructFieldType_String {
+		fbsType = "string"
+	}
+
+	return fbsType
+}
+
+func (this *FlatBuffersCodeGenerator) getTableColumnFbsType(
+	columnDef *TableColumnDef) string {
+
+	var checkType TableColumnType
+	if columnDef.Type == TableColumnType_List {
+		checkType = columnDef.ListType
+	} else {
+		checkType = columnDef.Type
+	}
+
+	fbsType := ""
+	if checkType == TableColumnType_Int {
+		fbsType = "int"
+	} else if checkType == TableColumnType_String {
+		fbsType = "string"
+	} else if checkType == TableColumnType_Struct {
+		fbsType = GetFlatBuffersStructTypeName(columnDef.RefStructDef)
+	}
+
+	if columnDef.Type == TableColumnType_List {
+		return fmt.Sprintf("[%s]", fbsType)
+	} else {
+		return fbsType
+	}
+}
+
+func (this *FlatBuffersCodeGenerator) generateGlobalStructFile(
+	structDef *StructDef) string {
+
+	var sb strings.Builder
+
+	this.writeDontEditComment(&sb)
+	this.writeNamespaceDecl(&sb)
+	this.writeStructDecl(&sb, structDef)
+
+	return sb.String()
+}
+
+func (this *FlatBuffersCodeGenerator) generateTableFile(
+	tableDef *TableDef) string {
+
+	var sb strings.Builder
+
+	this.writeDontEditComment(&sb)
+	this.writeTableFileIncludeFileDecl(&sb, tableDef)
+	this.writeNamespaceDecl(&sb)
+	for _, def := range tableDef.LocalStructs {
+		this.writeStructDecl(&sb, def)
+	}
+	this.writeTableDecl(&sb, tableDef)
+
+	this.writeEmptyLine(&sb)
+	this.writeLineFormat(&sb,
+		"root_type %s;",
+		tableDef.Name)
+
+	return sb.String()
+}
+
+func (this *FlatBuffersCodeGenerator) writeDontEditComment(
+	sb *strings.Builder) {
+
+	this.writeLine(sb,
+		"// Generated by brickred table compiler.")
+	this.writeLine(sb,
+		"// Do not edit unless you are sure that you know what you are doing.")
+}
+
+func (this *FlatBuffersCodeGenerator) writeDescriptionComment(
+	sb *strings.Builder, indent string, description string) {
+
+	if description == "" {
+		return
+	}
+
+	for line := range strings.SplitSeq(description, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			this.writeLineFormat(sb,
+				"%s//",
+				indent)
+		} else {
+			this.writeLineFormat(sb,
+				"%s// %s",
+				indent, line)
+		}
+	}
+}
+
+func (this *FlatBuffersCodeGenerator) writeTableFileIncludeFileDecl(
+	sb *strings.Builder, tableDef *TableDef) {
+
+	refStructDefs := make([]*StructDef, 0)
+	for _, columnDef := range tableDef.Columns {
+		def := columnDef.RefStructDef
+		if def == nil || def.ParentRef != nil {
+			continue
+		}
+		if slices.Contains(refStructDefs, def) {
+			continue
+		}
+		refStructDefs = append(refStructDefs, def)
+	}
+
+	if len(refStructDefs) > 0 {
+		this.writeEmptyLine(sb)
+	}
+	for _, def := range refStructDefs {
+		this.writeLineFormat(sb,
+			"include \"%s.fbs\";",
+			UtilCamelToUnderscore(def.Name))
+	}
+}
+
+func (this *FlatBuffersCodeGenerator) writeNamespaceDecl(
+	sb *strings.Builder) {
+
+	readerDef, ok := this.descriptor.Readers[this.reader]
+	if ok == false {
+		return
+	}
+	namespaceName := strings.Join(readerDef.NamespaceParts, ".")
+
+	this.writeEmptyLine(sb)
+	this.writeLineFormat(sb,
+		"namespace %s;",
+		namespaceName)
+}
+
+func (this *FlatBuffersCodeGenerator) writeStructDecl(
+	sb *strings.Builder, structDef *StructDef) {
+
+	this.writeEmptyLine(sb)
+	this.writeDescriptionComment(sb, "", structDef.Description)
+	this.writeLineFormat(sb,
+		"table %s {",
+		GetFlatBuffersStructTypeName(structDef))
+	for _, def := range structDef.Fields {
+		this.writeDescriptionComment(sb, "    ", def.Description)
+		this.writeLineFormat(sb,
+			"    %s:%s;",
+			def.Name, this.getStructFieldFbsType(def))
+	}
+	this.writeLine(sb,
+		"}")
+}
+
+func (this *FlatBuffersCodeGenerator) writeTableDecl(
+	sb *strings.Builder, tableDef *TableDef) {
+
+	// row
+	this.writeEmptyLine(sb)
+	this.writeLineFormat(sb,
+		"table %s_Row {",
+		tableDef.Name)
+	for _, def := range tableDef.Columns {
+		keyAttribute := ""
+		if def == tableDef.TableKey &&
+			tableDef.TableKeyType == TableKeyType_SingleKey {
+			keyAttribute = " (key)"
+		}
+		this.writeDescriptionComment(sb, "    ", def.Description)
+		this.writeLineFormat(sb,
+			"    %s:%s%s;",
+			def.Name, this.getTableColumnFbsType(def), keyAttribute)
+	}
+	this.writeLine(sb,
+		"}")
+
+	// row set
+	if tableDef.TableKeyType == TableKeyType_SetKey {
+		this.writeEmptyLine(sb)
+		this.writeLineFormat(sb,
+			"table %s_RowSet {",
+			tableDef.Name)
+		this.writeLineFormat(sb,
+			"    %s:%s (key);",
+			tableDef.TableKey.Name,
+			this.getTableColumnFbsType(tableDef.TableKey))
+		this.writeLineFormat(sb,
+			"    rows:[%s_Row];",
+			tableDef.Name)
+		this.writeLine(sb,
+			"}")
+	}
+
+	// table
+	this.writeEmptyLine(sb)
+	this.writeDescriptionComment(sb, "", tableDef.Description)
+	this.writeLineFormat(sb,
+		"table %s {",
+		tableDef.Name)
+	if tableDef.TableKind == TableKind_Config {
+		this.writeLineFormat(sb,
+			"    row:%s_Row;",
+			tableDef.Name)
+	} else if tableDef.TableKeyType == TableKeyType_SetKey {
+		this.writeLineFormat(sb,
+			"    row_sets:[%s_RowSet];",
+			tableDef.Name)
+	} else {
+		this.writeLineFormat(sb,
+			"    rows:[%s_Row];",
+			tableDef.Name)
+	}
+	this.writeLine(sb,
+		"}")
+}
@@ -0,0 +1,216 @@
+package lib
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+)
+
+// flatbuffers data file is the root type generated by
+// FlatBuffersCodeGenerator, rows or row sets are sorted by key value,
+// int fields of zero value are omitted except the key column
+
+type FlatBuffersDataExporter struct {
+	BaseDataExporter
+
+	builder *flatBuffersBuilder
+}
+
+func NewFlatBuffersDataExporter() *FlatBuffersDataExporter {
+	newObj := new(FlatBuffersDataExporter)
+	newObj.Diagnostics = NewDiagnosticList()
+
+	return newObj
+}
+
+func (this *FlatBuffersDataExporter) Close() {
+	this.builder = nil
+	this.close()
+}
+
+func (this *FlatBuffersDataExporter) Export(descriptor *TableDescriptor,
+	reader string, tableDatas []*TableData, outputDir string) bool {
+
+	this.init(descriptor, reader)
+
+	exportOk := true
+	for _, tableData := range tableDatas {
+		rowValues, ok := this.getRowValues(tableData)
+		if ok == false {
+			exportOk = false
+			continue
+		}
+
+		tableDef := tableData.TableDefRef
+		filePath := filepath.Join(outputDir,
+			this.getOutputFileName(tableDef, ".fb"))
+		fileContent := this.encodeTable(tableDef, rowValues)
+		if this.writeFile(filePath, fileContent) == false {
+			exportOk = false
+		}
+	}
+
+	return exportOk
+}
+
+func (this *FlatBuffersDataExporter) encodeTable(
+	tableDef *TableDef, rowValues [][]any) string {
+
+	this.builder = newFlatBuffersBuilder()
+
+	var rootOffset int
+	if tableDef.TableKind == TableKind_Config {
+		rowOffset := this.encodeRow(tableDef, rowValues[0])
+		this.builder.startObject(1)
+		this.builder.addOffset(0, rowOffset)
+		rootOffset = this.builder.endObject()
+	} else if tableDef.TableKeyType == TableKeyType_SetKey {
+		rootOffset = this.encodeRowSets(tableDef, rowValues)
+	} else {
+		keyColumnIndex := tableDef.TableKeyColumnIndex
+		sortedRowValues := slices.Clone(rowValues)
+		slices.SortStableFunc(sortedRowValues, func(a []any, b []any) int {
+			return this.compareKey(a[keyColumnIndex], b[keyColumnIndex])
+		})
+
+		rowOffsets := make([]int, 0, len(sortedRowValues))
+		for _, values := range sortedRowValues {
+			rowOffsets = append(rowOffsets, this.encodeRow(tableDef, values))
+		}
+		rowsOffset := this.builder.createOffsetVector(rowOffsets)
+		this.builder.startObject(1)
+		this.builder.addOffset(0, rowsOffset)
+		rootOffset = this.builder.endObject()
+	}
+
+	return string(this.builder.finish(rootOffset))
+}
+
+// rows of a set are contiguous and in data file order
+func (this *FlatBuffersDataExporter) encodeRowSets(
+	tableDef *TableDef, rowValues [][]any) int {
+
+	keyColumnIndex := tableDef.TableKeyColumnIndex
+
+	rowSets := make([][][]any, 0)
+	for i, values := range rowValues {
+		if i == 0 ||
+			values[keyColumnIndex] != rowValues[i-1][keyColumnIndex] {
+			rowSets = append(rowSets, make([][]any, 0))
+		}
+		rowSets[len(rowSets)-1] = append(rowSets[len(rowSets)-1], values)
+	}
+	slices.SortStableFunc(rowSets, func(a [][]any, b [][]any) int {
+		return this.compareKey(a[0][keyColumnIndex], b[0][keyColumnIndex])
+	})
+
+	rowSetOffsets := make([]int, 0, len(rowSets))
+	for _, rows := range rowSets {
+		rowOffsets := make([]int, 0, len(rows))
+		for _, values := range rows {
+			rowOffsets = append(rowOffsets, this.encodeRow(tableDef, values))
+		}
+		rowsOffset := this.builder.createOffsetVector(rowOffsets)
+
+		key := rows[0][keyColumnIndex]
+		keyOffset := 0
+		if v, ok := key.(string); ok {
+			keyOffset = this.builder.createString(v)
+		}
+		this.builder.startObject(2)
+		if v, ok := key.(int32); ok {
+			this.builder.addInt32(0, v)
+		} else {
+			this.builder.addOffset(0, keyOffset)
+		}
+		this.builder.addOffset(1, rowsOffset)
+		rowSetOffsets = append(rowSetOffsets, this.builder.endObject())
+	}
+	rowSetsOffset := this.builder.createOffsetVector(rowSetOffsets)
+
+	this.builder.startObject(1)
+	this.builder.addOffset(0, rowSetsOffset)
+
+	return this.builder.endObject()
+}
+
+// int key is compared by value, string key is compared by bytes
+func (this *FlatBuffersDataExporter) compareKey(a any, b any) int {
+	if v, ok := a.(int32); ok {
+		return cmp.Compare(v, b.(int32))
+	}
+
+	return cmp.Compare(a.(string), b.(string))
+}
+
+// children are created before the row table
+func (this *FlatBuffersDataExporter) encodeRow(
+	tableDef *TableDef, values []any) int {
+
+	offsets := make([]int, len(tableDef.Columns))
+	for i, def := range tableDef.Columns {
+		offsets[i] = this.encodeColumnValue(def, values[i])
+	}
+
+	this.builder.startObject(len(tableDef.Columns))
+	for i, def := range tableDef.Columns {
+		if v, ok := values[i].(int32); ok == false {
+			this.builder.addOffset(i, offsets[i])
+		} else if v != 0 || def == tableDef.TableKey {
+			this.builder.addInt32(i, v)
+		}
+	}
+
+	return this.builder.endObject()
+}
+
+// return offset of the value, 0 when the value is int
+func (this *FlatBuffersDataExporter) encodeColumnValue(
+	columnDef *TableColumnDef, value any) int {
+
+	if columnDef.Type != TableColumnType_List {
+		return this.encodeValue(value)
+	}
+
+	items := value.([]any)
+	if columnDef.ListType == TableColumnType_Int {
+		ints := make([]int32, 0, len(items))
+		for _, item := range items {
+			ints = append(ints, item.(int32))
+		}
+		return this.builder.createInt32Vector(ints)
+	}
+
+	itemOffsets := make([]int, 0, len(items))
+	for _, item := range items {
+		itemOffsets = append(itemOffsets, this.encodeValue(item))
+	}
+
+	return this.builder.createOffsetVector(itemOffsets)
+}
+
+// struct value is a table of its field values
+func (this *FlatBuffersDataExporter) encodeValue(value any) int {
+	switch v := value.(type) {
+	case string:
+		return this.builder.createString(v)
+	case []any:
+		fieldOffsets := make([]int, len(v))
+		for i, field := range v {
+			if s, ok := field.(string); ok {
+				fieldOffsets[i] = this.builder.createString(s)
+			}
+		}
+		this.builder.startObject(len(v))
+		for i, field := range v {
+			if n, ok := field.(int32); ok == false {
+				this.builder.addOffset(i, fieldOffsets[i])
+			} else if n != 0 {
+				this.builder.addInt32(i, n)
+			}
+		}
+		return this.builder.endObject()
+	}
+
+	return 0
+}
@@ -0,0 +1,130 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+)
+
+// json data file layout:
+//   single key table -> object of rows keyed by key value
+//   set key table    -> object of row arrays keyed by key value
+//   config table     -> object of values keyed by name
+//   row and struct   -> object of values keyed by column or field name
+//   list             -> array of values
+// keys are in data file order
+
+type JsonDataExporter struct {
+	BaseDataExporter
+}
+
+func NewJsonDataExporter() *JsonDataExporter {
+	newObj := new(JsonDataExporter)
+	newObj.Diagnostics = NewDiagnosticList()
+
+	return newObj
+}
+
+func (this *JsonDataExporter) Close() {
+	this.close()
+}
+
+func (this *JsonDataExporter) Export(descriptor *TableDescriptor,
+	reader string, tableDatas []*TableData, outputDir string) bool {
+
+	this.init(descriptor, reader)
+
+	exportOk := true
+	for _, tableData := range tableDatas {
+		rowValues, ok := this.getRowValues(tableData)
+		if ok == false {
+			exportOk = false
+			continue
+		}
+
+		tableDef := tableData.TableDefRef
+		filePath := filepath.Join(outputDir,
+			this.getOutputFileName(tableDef, ".json"))
+		fileContent := this.encodeTable(tableDef, rowValues)
+		if this.writeFile(filePath, fileContent) == false {
+			exportOk = false
+		}
+	}
+
+	return exportOk
+}
+
+func (this *JsonDataExporter) encodeTable(
+	tableDef *TableDef, rowValues [][]any) string {
+
+	buffer := make([]byte, 0)
+
+	if tableDef.TableKind == TableKind_Config {
+		buffer = this.appendRow(buffer, tableDef, rowValues[0])
+	} else {
+		keyColumnIndex := tableDef.TableKeyColumnIndex
+		isSetKey := tableDef.TableKeyType == TableKeyType_SetKey
+
+		buffer = append(buffer, '{')
+		for i, values := range rowValues {
+			key := this.getKeyText(values[keyColumnIndex])
+			newSet := i == 0 ||
+				key != this.getKeyText(rowValues[i-1][keyColumnIndex])
+
+			if isSetKey == false || newSet {
+				if isSetKey && i > 0 {
+					buffer = append(buffer, ']')
+				}
+				if i > 0 {
+					buffer = append(buffer, ',')
+				}
+				buffer = this.appendJsonString(buffer, key)
+				buffer = append(buffer, ':')
+				if isSetKey {
+					buffer = append(buffer, '[')
+				}
+			} else {
+				buffer = append(buffer, ',')
+			}
+			buffer = this.appendRow(buffer, tableDef, values)
+		}
+		if isSetKey && len(rowValues) > 0 {
+			buffer = append(buffer, ']')
+		}
+		buffer = append(buffer, '}')
+	}
+
+	// buffer is always valid json
+	var out bytes.Buffer
+	json.Indent(&out, buffer, "", "  ")
+	out.WriteByte('\n')
+
+	return out.String()
+}
+
+// json object key is always string
+func (this *JsonDataExporter) getKeyText(value any) string {
+	if v, ok := value.(int32); ok {
+		return strconv.FormatInt(int64(v), 10)
+	}
+
+	return value.(string)
+}
+
+func (this *JsonDataExporter) appendRow(
+	buffer []byte, tableDef *TableDef, values []any) []byte {
+
+	buffer = append(buffer, '{')
+	for i, def := range tableDef.Columns {
+		if i > 0 {
+			buffer = append(buffer, ',')
+		}
+		buffer = this.appendJsonString(buffer, def.Name)
+		buffer = append(buffer, ':')
+		buffer = this.appendJsonColumnValue(buffer, def, values[i])
+	}
+	buffer = append(buffer, '}')
+
+	return buffer
+}
@@ -0,0 +1,228 @@
+package lib
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+)
+
+// lua data file layout, the file returns a table:
+//   single key table -> rows keyed by key value
+//   set key table    -> row arrays keyed by key value
+//   config table     -> values keyed by name
+//   row and struct   -> values keyed by column or field name
+//   list             -> array of values
+// int key stays numeric, rows are in data file order
+
+type LuaDataExporter struct {
+	BaseDataExporter
+}
+
+// names can not be used as lua table field without quoting
+var g_luaKeywords = map[string]bool{
+	"and": true, "break": true, "do": true, "else": true,
+	"elseif": true, "end": true, "false": true, "for": true,
+	"function": true, "goto": true, "if": true, "in": true,
+	"local": true, "nil": true, "not": true, "or": true,
+	"repeat": true, "return": true, "then": true, "true": true,
+	"until": true, "while": true,
+}
+
+func NewLuaDataExporter() *LuaDataExporter {
+	newObj := new(LuaDataExporter)
+	newObj.Diagnostics = NewDiagnosticList()
+
+	return newObj
+}
+
+func (this *LuaDataExporter) Close() {
+	this.close()
+}
+
+func (this *LuaDataExporter) Export(descriptor *TableDescriptor,
+	reader string, tableDatas []*TableData, outputDir string) bool {
+
+	this.init(descriptor, reader)
+
+	exportOk := true
+	for _, tableData := range tableDatas {
+		rowValues, ok := this.getRowValues(tableData)
+		if ok == false {
+			exportOk = false
+			continue
+		}
+
+		tableDef := tableData.TableDefRef
+		filePath := filepath.Join(outputDir,
+			this.getOutputFileName(tableDef, ".lua"))
+		fileContent := this.encodeTable(tableDef, rowValues)
+		if this.writeFile(filePath, fileContent) == false {
+			exportOk = false
+		}
+	}
+
+	return exportOk
+}
+
+func (this *LuaDataExporter) encodeTable(
+	tableDef *TableDef, rowValues [][]any) string {
+
+	buffer := make([]byte, 0)
+	buffer = append(buffer, "return {\n"...)
+
+	if tableDef.TableKind == TableKind_Config {
+		for i, def := range tableDef.Columns {
+			buffer = append(buffer, "  "...)
+			buffer = this.appendLuaKey(buffer, def.Name)
+			buffer = append(buffer, " = "...)
+			buffer = this.appendLuaColumnValue(
+				buffer, def, rowValues[0][i])
+			buffer = append(buffer, ",\n"...)
+		}
+	} else {
+		keyColumnIndex := tableDef.TableKeyColumnIndex
+		isSetKey := tableDef.TableKeyType == TableKeyType_SetKey
+
+		for i, values := range rowValues {
+			key := values[keyColumnIndex]
+			newSet := i == 0 || key != rowValues[i-1][keyColumnIndex]
+
+			if isSetKey == false || newSet {
+				if isSetKey && i > 0 {
+					buffer = append(buffer, "  },\n"...)
+				}
+				buffer = append(buffer, "  ["...)
+				buffer = this.appendLuaValue(buffer, nil, key)
+				buffer = append(buffer, "] = "...)
+				if isSetKey {
+					buffer = append(buffer, "{\n"...)
+				}
+			}
+			if isSetKey {
+				buffer = append(buffer, "    "...)
+			}
+			buffer = this.appendRow(buffer, tableDef, values)
+			buffer = append(buffer, ",\n"...)
+		}
+		if isSetKey && len(rowValues) > 0 {
+			buffer = append(buffer, "  },\n"...)
+		}
+	}
+
+	buffer = append(buffer, "}\n"...)
+
+	return string(buffer)
+}
+
+func (this *LuaDataExporter) appendRow(
+	buffer []byte, tableDef *TableDef, values []any) []byte {
+
+	buffer = append(buffer, "{ "...)
+	for i, def := range tableDef.Columns {
+		if i > 0 {
+			buffer = append(buffer, ", "...)
+		}
+		buffer = this.appendLuaKey(buffer, def.Name)
+		buffer = append(buffer, " = "...)
+		buffer = this.appendLuaColumnValue(buffer, def, values[i])
+	}
+	buffer = append(buffer, " }"...)
+
+	return buffer
+}
+
+// value is parsed by ParseTableColumnValue
+func (this *LuaDataExporter) appendLuaColumnValue(
+	buffer []byte, columnDef *TableColumnDef, value any) []byte {
+
+	if columnDef.Type != TableColumnType_List {
+		return this.appendLuaValue(buffer, columnDef.RefStructDef, value)
+	}
+
+	items := value.([]any)
+	if len(items) == 0 {
+		return append(buffer, "{}"...)
+	}
+	buffer = append(buffer, "{ "...)
+	for i, item := range items {
+		if i > 0 {
+			buffer = append(buffer, ", "...)
+		}
+		buffer = this.appendLuaValue(buffer, columnDef.RefStructDef, item)
+	}
+	buffer = append(buffer, " }"...)
+
+	return buffer
+}
+
+// structDef is used when value is a struct
+func (this *LuaDataExporter) appendLuaValue(
+	buffer []byte, structDef *StructDef, value any) []byte {
+
+	switch v := value.(type) {
+	case int32:
+		buffer = strconv.AppendInt(buffer, int64(v), 10)
+	case string:
+		buffer = this.appendLuaString(buffer, v)
+	case []any:
+		buffer = append(buffer, "{ "...)
+		for i, def := range structDef.Fields {
+			if i > 0 {
+				buffer = append(buffer, ", "...)
+			}
+			buffer = this.appendLuaKey(buffer, def.Name)
+			buffer = append(buffer, " = "...)
+			buffer = this.appendLuaValue(buffer, nil, v[i])
+		}
+		buffer = append(buffer, " }"...)
+	}
+
+	return buffer
+}
+
+// name is quoted when it is a keyword
+func (this *LuaDataExporter) appendLuaKey(
+	buffer []byte, name string) []byte {
+
+	if g_luaKeywords[name] == false {
+		return append(buffer, name...)
+	}
+
+	buffer = append(buffer, '[')
+	buffer = this.appendLuaString(buffer, name)
+	buffer = append(buffer, ']')
+
+	return buffer
+}
+
+// control characters are escaped as decimal,
+// other bytes are kept as is, lua string is byte string
+func (this *LuaDataExporter) appendLuaString(
+	buffer []byte, s string) []byte {
+
+	buffer = append(buffer, '"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"':
+			buffer = append(buffer, "\\\""...)
+		case '\\':
+			buffer = append(buffer, "\\\\"...)
+		case '\n':
+			buffer = append(buffer, "\\n"...)
+		case '\r':
+			buffer = append(buffer, "\\r"...)
+		case '\t':
+			buffer = append(buffer, "\\t"...)
+		default:
+			if c < 0x20 || c == 0x7f {
+				buffer = fmt.Appendf(buffer, "\\%03d", c)
+			} else {
+				buffer = append(buffer, c)
+			}
+		}
+	}
+	buffer = append(buffer, '"')
+
+	return buffer
+}
@@ -0,0 +1,231 @@
+package lib
+
+import (
+	"encoding/binary"
+	"path/filepath"
+)
+
+// msgpack data file layout, the same as json except keys are typed:
+//   single key table -> map of rows keyed by key value
+//   set key table    -> map of row arrays keyed by key value
+//   config table     -> map of values keyed by name
+//   row and struct   -> map of values keyed by column or field name
+//   list             -> array of values
+// with schema hash, the file is a map of `schema_hash` and `data`,
+// schema_hash is the table schema fingerprint, see GetTableSchemaFingerprint
+
+type MsgpackDataExporter struct {
+	BaseDataExporter
+
+	schemaHash bool
+}
+
+func NewMsgpackDataExporter(schemaHash bool) *MsgpackDataExporter {
+	newObj := new(MsgpackDataExporter)
+	newObj.Diagnostics = NewDiagnosticList()
+	newObj.schemaHash = schemaHash
+
+	return newObj
+}
+
+func (this *MsgpackDataExporter) Close() {
+	this.close()
+}
+
+func (this *MsgpackDataExporter) Export(descriptor *TableDescriptor,
+	reader string, tableDatas []*TableData, outputDir string) bool {
+
+	this.init(descriptor, reader)
+
+	exportOk := true
+	for _, tableData := range tableDatas {
+		rowValues, ok := this.getRowValues(tableData)
+		if ok == false {
+			exportOk = false
+			continue
+		}
+
+		tableDef := tableData.TableDefRef
+		filePath := filepath.Join(outputDir,
+			this.getOutputFileName(tableDef, ".msgpack"))
+		fileContent := this.encodeTable(tableDef, rowValues)
+		if this.writeFile(filePath, fileContent) == false {
+			exportOk = false
+		}
+	}
+
+	return exportOk
+}
+
+func (this *MsgpackDataExporter) encodeTable(
+	tableDef *TableDef, rowValues [][]any) string {
+
+	buffer := make([]byte, 0)
+	if this.schemaHash {
+		buffer = this.appendMapHeader(buffer, 2)
+		buffer = this.appendString(buffer, "schema_hash")
+		buffer = this.appendInt(buffer,
+			int64(GetTableSchemaFingerprint(tableDef)))
+		buffer = this.appendString(buffer, "data")
+	}
+
+	if tableDef.TableKind == TableKind_Config {
+		buffer = this.appendRow(buffer, tableDef, rowValues[0])
+		return string(buffer)
+	}
+
+	keyColumnIndex := tableDef.TableKeyColumnIndex
+	if tableDef.TableKeyType == TableKeyType_SingleKey {
+		buffer = this.appendMapHeader(buffer, len(rowValues))
+		for _, values := range rowValues {
+			buffer = this.appendValue(buffer, nil, values[keyColumnIndex])
+			buffer = this.appendRow(buffer, tableDef, values)
+		}
+		return string(buffer)
+	}
+
+	// rows of a set are contiguous
+	sets := make([][][]any, 0)
+	for i, values := range rowValues {
+		if i == 0 ||
+			values[keyColumnIndex] != rowValues[i-1][keyColumnIndex] {
+			sets = append(sets, make([][]any, 0))
+		}
+		sets[len(sets)-1] = append(sets[len(sets)-1], values)
+	}
+	buffer = this.appendMapHeader(buffer, len(sets))
+	for _, set := range sets {
+		buffer = this.appendValue(buffer, nil, set[0][keyColumnIndex])
+		buffer = this.appendArrayHeader(buffer, len(set))
+		for _, values := range set {
+			buffer = this.appendRow(buffer, tableDef, values)
+		}
+	}
+
+	return string(buffer)
+}
+
+func (this *MsgpackDataExporter) appendRow(
+	buffer []byte, tableDef *TableDef, values []any) []byte {
+
+	buffer = this.appendMapHeader(buffer, len(tableDef.Columns))
+	for i, def := range tableDef.Columns {
+		buffer = this.appendString(buffer, def.Name)
+		buffer = this.appendColumnValue(buffer, def, values[i])
+	}
+
+	return buffer
+}
+
+// value is parsed by ParseTableColumnValue
+func (this *MsgpackDataExporter) appendColumnValue(
+	buffer []byte, columnDef *TableColumnDef, value any) []byte {
+
+	if columnDef.Type != TableColumnType_List {
+		return this.appendValue(buffer, columnDef.RefStructDef, value)
+	}
+
+	items := value.([]any)
+	buffer = this.appendArrayHeader(buffer, len(items))
+	for _, item := range items {
+		buffer = this.appendValue(buffer, columnDef.RefStructDef, item)
+	}
+
+	return buffer
+}
+
+// structDef is used when value is a struct
+func (this *MsgpackDataExporter) appendValue(
+	buffer []byte, structDef *StructDef, value any) []byte {
+
+	switch v := value.(type) {
+	case int32:
+		buffer = this.appendInt(buffer, int64(v))
+	case string:
+		buffer = this.appendString(buffer, v)
+	case []any:
+		buffer = this.appendMapHeader(buffer, len(structDef.Fields))
+		for i, def := range structDef.Fields {
+			buffer = this.appendString(buffer, def.Name)
+			buffer = this.appendValue(buffer, nil, v[i])
+		}
+	}
+
+	return buffer
+}
+
+// the smallest format of the value
+func (this *MsgpackDataExporter) appendInt(buffer []byte, v int64) []byte {
+	if v >= 0 {
+		if v <= 0x7f {
+			return append(buffer, byte(v))
+		} else if v <= 0xff {
+			return append(buffer, 0xcc, byte(v))
+		} else if v <= 0xffff {
+			return binary.BigEndian.AppendUint16(
+				append(buffer, 0xcd), uint16(v))
+		} else {
+			return binary.BigEndian.AppendUint32(
+				append(buffer, 0xce), uint32(v))
+		}
+	}
+
+	if v >= -32 {
+		return append(buffer, byte(v))
+	} else if v >= -1<<7 {
+		return append(buffer, 0xd0, byte(v))
+	} else if v >= -1<<15 {
+		return binary.BigEndian.AppendUint16(
+			append(buffer, 0xd1), uint16(v))
+	} else {
+		return binary.BigEndian.AppendUint32(
+			append(buffer, 0xd2), uint32(v))
+	}
+}
+
+func (this *MsgpackDataExporter) appendString(
+	buffer []byte, s string) []byte {
+
+	n := len(s)
+	if n < 32 {
+		buffer = append(buffer, 0xa0|byte(n))
+	} else if n <= 0xff {
+		buffer = append(buffer, 0xd9, byte(n))
+	} else if n <= 0xffff {
+		buffer = binary.BigEndian.AppendUint16(
+			append(buffer, 0xda), uint16(n))
+	} else {
+		buffer = binary.BigEndian.AppendUint32(
+			append(buffer, 0xdb), uint32(n))
+	}
+
+	return append(buffer, s...)
+}
+
+func (this *MsgpackDataExporter) appendArrayHeader(
+	buffer []byte, n int) []byte {
+
+	if n < 16 {
+		return append(buffer, 0x90|byte(n))
+	} else if n <= 0xffff {
+		return binary.BigEndian.AppendUint16(
+			append(buffer, 0xdc), uint16(n))
+	} else {
+		return binary.BigEndian.AppendUint32(
+			append(buffer, 0xdd), uint32(n))
+	}
+}
+
+func (this *MsgpackDataExporter) appendMapHeader(
+	buffer []byte, n int) []byte {
+
+	if n < 16 {
+		return append(buffer, 0x80|byte(n))
+	} else if n <= 0xffff {
+		return binary.BigEndian.AppendUint16(
+			append(buffer, 0xde), uint16(n))
+	} else {
+		return binary.BigEndian.AppendUint32(
+			append(buffer, 0xdf), uint32(n))
+	}
+}
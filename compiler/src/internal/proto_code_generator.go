@@ -0,0 +1,213 @@
+package lib
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// all structs and tables are in one proto file named as the reader,
+// local struct and row are nested messages of the table message,
+// field number is the define order of the field or column,
+// data file of ProtobufDataExporter is the table message
+
+type ProtoCodeGenerator struct {
+	BaseCodeGenerator
+}
+
+func NewProtoCodeGenerator() *ProtoCodeGenerator {
+	newObj := new(ProtoCodeGenerator)
+	newObj.Diagnostics = NewDiagnosticList()
+
+	return newObj
+}
+
+func (this *ProtoCodeGenerator) Close() {
+	this.close()
+}
+
+func (this *ProtoCodeGenerator) Generate(
+	descriptor *TableDescriptor,
+	reader string, outputDir string, newLineType NewLineType) bool {
+
+	this.init(descriptor, reader, newLineType)
+
+	fileName := "table.proto"
+	if reader != "" {
+		fileName = reader + ".proto"
+	}
+	filePath := filepath.Join(outputDir, fileName)
+	fileContent := this.generateProtoFile()
+
+	return this.writeFile(filePath, fileContent)
+}
+
+func (this *ProtoCodeGenerator) getStructFieldProtoType(
+	fieldDef *StructFieldDef) string {
+
+	protoType := ""
+	if fieldDef.Type == StructFieldType_Int {
+		protoType = "int32"
+	} else if fieldDef.Type == StructFieldType_String {
+		protoType = "string"
+	}
+
+	return protoType
+}
+
+func (this *ProtoCodeGenerator) getTableColumnProtoType(
+	columnDef *TableColumnDef) string {
+
+	var checkType TableColumnType
+	if columnDef.Type == TableColumnType_List {
+		checkType = columnDef.ListType
+	} else {
+		checkType = columnDef.Type
+	}
+
+	protoType := ""
+	if checkType == TableColumnType_Int {
+		protoType = "int32"
+	} else if checkType == TableColumnType_String {
+		protoType = "string"
+	} else if checkType == TableColumnType_Struct {
+		protoType = columnDef.RefStructDef.Name
+	}
+
+	if columnDef.Type == TableColumnType_List {
+		return fmt.Sprintf("repeated %s", protoType)
+	} else {
+		return protoType
+	}
+}
+
+func (this *ProtoCodeGenerator) generateProtoFile() string {
+	var sb strings.Builder
+
+	this.writeDontEditComment(&sb)
+	this.writeEmptyLine(&sb)
+	this.writeLine(&sb,
+		"syntax = \"proto3\";")
+	this.writePackageDecl(&sb)
+
+	for _, def := range this.descriptor.GlobalStructs {
+		this.writeStructDecl(&sb, def)
+	}
+	for _, def := range this.descriptor.Tables {
+		this.writeTableDecl(&sb, def)
+	}
+
+	return sb.String()
+}
+
+func (this *ProtoCodeGenerator) writeDontEditComment(
+	sb *strings.Builder) {
+
+	this.writeLine(sb,
+		"// Generated by brickred table compiler.")
+	this.writeLine(sb,
+		"// Do not edit unless you are sure that you know what you are doing.")
+}
+
+func (this *ProtoCodeGenerator) writeDescriptionComment(
+	sb *strings.Builder, indent string, description string) {
+
+	if description == "" {
+		return
+	}
+
+	for line := range strings.SplitSeq(description, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			this.writeLineFormat(sb,
+				"%s//",
+				indent)
+		} else {
+			this.writeLineFormat(sb,
+				"%s// %s",
+				indent, line)
+		}
+	}
+}
+
+func (this *ProtoCodeGenerator) writePackageDecl(
+	sb *strings.Builder) {
+
+	readerDef, ok := this.descriptor.Readers[this.reader]
+	if ok == false {
+		return
+	}
+	packageName := strings.Join(readerDef.NamespaceParts, ".")
+
+	this.writeEmptyLine(sb)
+	this.writeLineFormat(sb,
+		"package %s;",
+		packageName)
+}
+
+func (this *ProtoCodeGenerator) writeStructDecl(
+	sb *strings.Builder, structDef *StructDef) {
+
+	var indent string
+	if structDef.ParentRef == nil {
+		indent = ""
+	} else {
+		indent = "    "
+	}
+
+	if structDef.ParentRef == nil {
+		this.writeEmptyLine(sb)
+	}
+	this.writeDescriptionComment(sb, indent, structDef.Description)
+	this.writeLineFormat(sb,
+		"%smessage %s {",
+		indent, structDef.Name)
+	for i, def := range structDef.Fields {
+		this.writeDescriptionComment(sb, indent+"    ", def.Description)
+		this.writeLineFormat(sb,
+			"%s    %s %s = %d;",
+			indent, this.getStructFieldProtoType(def), def.Name, i+1)
+	}
+	this.writeLineFormat(sb,
+		"%s}",
+		indent)
+}
+
+// config table has one row,
+// rows of a set key table are in data file order
+func (this *ProtoCodeGenerator) writeTableDecl(
+	sb *strings.Builder, tableDef *TableDef) {
+
+	this.writeEmptyLine(sb)
+	this.writeDescriptionComment(sb, "", tableDef.Description)
+	this.writeLineFormat(sb,
+		"message %s {",
+		tableDef.Name)
+
+	for _, def := range tableDef.LocalStructs {
+		this.writeStructDecl(sb, def)
+		this.writeEmptyLine(sb)
+	}
+
+	this.writeLine(sb,
+		"    message Row {")
+	for i, def := range tableDef.Columns {
+		this.writeDescriptionComment(sb, "        ", def.Description)
+		this.writeLineFormat(sb,
+			"        %s %s = %d;",
+			this.getTableColumnProtoType(def), def.Name, i+1)
+	}
+	this.writeLine(sb,
+		"    }")
+
+	this.writeEmptyLine(sb)
+	if tableDef.TableKind == TableKind_Config {
+		this.writeLine(sb,
+			"    Row row = 1;")
+	} else {
+		this.writeLine(sb,
+			"    repeated Row rows = 1;")
+	}
+	this.writeLine(sb,
+		"}")
+}
@@ -0,0 +1,143 @@
+package lib
+
+import (
+	"encoding/binary"
+	"path/filepath"
+)
+
+// protobuf data file is the table message generated by ProtoCodeGenerator,
+// values are encoded in proto3 wire format:
+//   int    -> varint, repeated int is packed
+//   string -> length delimited
+//   struct -> length delimited message
+// fields of default value are omitted except struct
+
+const protobufWireType_Varint = 0
+const protobufWireType_Bytes = 2
+
+type ProtobufDataExporter struct {
+	BaseDataExporter
+}
+
+func NewProtobufDataExporter() *ProtobufDataExporter {
+	newObj := new(ProtobufDataExporter)
+	newObj.Diagnostics = NewDiagnosticList()
+
+	return newObj
+}
+
+func (this *ProtobufDataExporter) Close() {
+	this.close()
+}
+
+func (this *ProtobufDataExporter) Export(descriptor *TableDescriptor,
+	reader string, tableDatas []*TableData, outputDir string) bool {
+
+	this.init(descriptor, reader)
+
+	exportOk := true
+	for _, tableData := range tableDatas {
+		rowValues, ok := this.getRowValues(tableData)
+		if ok == false {
+			exportOk = false
+			continue
+		}
+
+		tableDef := tableData.TableDefRef
+		filePath := filepath.Join(outputDir,
+			this.getOutputFileName(tableDef, ".pb"))
+		fileContent := this.encodeTable(tableDef, rowValues)
+		if this.writeFile(filePath, fileContent) == false {
+			exportOk = false
+		}
+	}
+
+	return exportOk
+}
+
+// rows or the row of config table is field 1 of the table message
+func (this *ProtobufDataExporter) encodeTable(
+	tableDef *TableDef, rowValues [][]any) string {
+
+	buffer := make([]byte, 0)
+	for _, values := range rowValues {
+		row := make([]byte, 0)
+		for i, def := range tableDef.Columns {
+			row = this.appendColumnValue(row, i+1, def, values[i])
+		}
+		buffer = this.appendBytesField(buffer, 1, row)
+	}
+
+	return string(buffer)
+}
+
+// value is parsed by ParseTableColumnValue
+func (this *ProtobufDataExporter) appendColumnValue(buffer []byte,
+	fieldNumber int, columnDef *TableColumnDef, value any) []byte {
+
+	if columnDef.Type != TableColumnType_List {
+		return this.appendValue(buffer, fieldNumber, value, false)
+	}
+
+	items := value.([]any)
+	if len(items) == 0 {
+		return buffer
+	}
+	if columnDef.ListType != TableColumnType_Int {
+		for _, item := range items {
+			buffer = this.appendValue(buffer, fieldNumber, item, true)
+		}
+		return buffer
+	}
+
+	packed := make([]byte, 0, len(items))
+	for _, item := range items {
+		packed = binary.AppendUvarint(
+			packed, uint64(int64(item.(int32))))
+	}
+
+	return this.appendBytesField(buffer, fieldNumber, packed)
+}
+
+// default value is written when the value is a list item
+func (this *ProtobufDataExporter) appendValue(buffer []byte,
+	fieldNumber int, value any, isListItem bool) []byte {
+
+	switch v := value.(type) {
+	case int32:
+		if v == 0 && isListItem == false {
+			return buffer
+		}
+		buffer = this.appendTag(
+			buffer, fieldNumber, protobufWireType_Varint)
+		buffer = binary.AppendUvarint(buffer, uint64(int64(v)))
+	case string:
+		if v == "" && isListItem == false {
+			return buffer
+		}
+		buffer = this.appendBytesField(buffer, fieldNumber, []byte(v))
+	case []any:
+		message := make([]byte, 0)
+		for i, field := range v {
+			message = this.appendValue(message, i+1, field, false)
+		}
+		buffer = this.appendBytesField(buffer, fieldNumber, message)
+	}
+
+	return buffer
+}
+
+func (this *ProtobufDataExporter) appendBytesField(
+	buffer []byte, fieldNumber int, data []byte) []byte {
+
+	buffer = this.appendTag(buffer, fieldNumber, protobufWireType_Bytes)
+	buffer = binary.AppendUvarint(buffer, uint64(len(data)))
+
+	return append(buffer, data...)
+}
+
+func (this *ProtobufDataExporter) appendTag(
+	buffer []byte, fieldNumber int, wireType int) []byte {
+
+	return binary.AppendUvarint(buffer, uint64(fieldNumber<<3|wireType))
+}
@@ -0,0 +1,110 @@
+package lib
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// all tables are written to one sqlite database file named as the reader,
+// table and column names are the same as define,
+// int column is INTEGER, string column is TEXT,
+// struct and list column is TEXT of json, queried by json functions,
+// config table has one row
+
+type SqliteDataExporter struct {
+	BaseDataExporter
+}
+
+func NewSqliteDataExporter() *SqliteDataExporter {
+	newObj := new(SqliteDataExporter)
+	newObj.Diagnostics = NewDiagnosticList()
+
+	return newObj
+}
+
+func (this *SqliteDataExporter) Close() {
+	this.close()
+}
+
+func (this *SqliteDataExporter) Export(descriptor *TableDescriptor,
+	reader string, tableDatas []*TableData, outputDir string) bool {
+
+	this.init(descriptor, reader)
+
+	writer := NewSqliteWriter()
+	defer writer.Close()
+
+	filePath := filepath.Join(outputDir, reader+".db")
+	exportOk := true
+	for _, tableData := range tableDatas {
+		rowValues, ok := this.getRowValues(tableData)
+		if ok == false {
+			exportOk = false
+			continue
+		}
+
+		tableDef := tableData.TableDefRef
+		rows := make([][]any, 0, len(rowValues))
+		for _, values := range rowValues {
+			rows = append(rows, this.getSqliteValues(tableDef, values))
+		}
+		if err := writer.AddTable(tableDef.Name,
+			this.getCreateTableSql(tableDef), rows); err != nil {
+			this.Diagnostics.AddError("export-failed", filePath, 0, 0,
+				"export sqlite failed: %s", err.Error())
+			exportOk = false
+		}
+	}
+	if exportOk == false {
+		return false
+	}
+
+	fileContent, err := writer.Bytes()
+	if err != nil {
+		this.Diagnostics.AddError("export-failed", filePath, 0, 0,
+			"export sqlite failed: %s", err.Error())
+		return false
+	}
+
+	return this.writeFile(filePath, string(fileContent))
+}
+
+func (this *SqliteDataExporter) getCreateTableSql(tableDef *TableDef) string {
+	columnDecls := make([]string, 0, len(tableDef.Columns))
+	for _, def := range tableDef.Columns {
+		sqlType := "TEXT"
+		if def.Type == TableColumnType_Int {
+			sqlType = "INTEGER"
+		}
+		columnDecls = append(columnDecls, fmt.Sprintf("%s %s",
+			this.quoteSqlName(def.Name), sqlType))
+	}
+
+	return fmt.Sprintf("CREATE TABLE %s (%s)",
+		this.quoteSqlName(tableDef.Name), strings.Join(columnDecls, ", "))
+}
+
+func (this *SqliteDataExporter) quoteSqlName(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// values parsed by ParseTableColumnValue to sqlite values
+func (this *SqliteDataExporter) getSqliteValues(
+	tableDef *TableDef, values []any) []any {
+
+	sqliteValues := make([]any, 0, len(values))
+	for i, def := range tableDef.Columns {
+		switch v := values[i].(type) {
+		case int32:
+			sqliteValues = append(sqliteValues, int64(v))
+		case string:
+			sqliteValues = append(sqliteValues, v)
+		default:
+			sqliteValues = append(sqliteValues,
+				string(this.appendJsonColumnValue(nil, def, v)))
+		}
+	}
+
+	return sqliteValues
+}
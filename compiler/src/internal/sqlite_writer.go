@@ -0,0 +1,370 @@
+package lib
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// write-only builder of sqlite database file,
+// tables are written as rowid tables without index,
+// values are int64, string or nil
+type SqliteWriter struct {
+	// page n is pages[n-1], page 1 is the schema table root
+	pages  [][]byte
+	tables []*sqliteTable
+}
+
+type sqliteTable struct {
+	name     string
+	sql      string
+	rootPage int
+}
+
+// cell of a table b-tree page, key is the max rowid of child page
+// for interior page
+type sqliteCell struct {
+	data  []byte
+	key   int64
+	child int
+}
+
+const sqlitePageSize = 4096
+const sqliteFileHeaderSize = 100
+
+func NewSqliteWriter() *SqliteWriter {
+	newObj := new(SqliteWriter)
+	newObj.pages = make([][]byte, 1)
+	newObj.pages[0] = make([]byte, sqlitePageSize)
+	newObj.tables = make([]*sqliteTable, 0)
+
+	return newObj
+}
+
+func (this *SqliteWriter) Close() {
+	this.tables = nil
+	this.pages = nil
+}
+
+// sql is the create table statement of the table,
+// rowid of rows starts from 1
+func (this *SqliteWriter) AddTable(
+	name string, sql string, rows [][]any) error {
+
+	cells := make([]*sqliteCell, 0, len(rows))
+	for i, values := range rows {
+		rowid := int64(i + 1)
+		cells = append(cells, &sqliteCell{
+			this.encodeLeafCell(rowid, this.encodeRecord(values)),
+			rowid, 0})
+	}
+
+	rootPage, err := this.buildTree(cells, 0)
+	if err != nil {
+		return fmt.Errorf("table `%s` %s", name, err.Error())
+	}
+	this.tables = append(this.tables, &sqliteTable{name, sql, rootPage})
+
+	return nil
+}
+
+// content of the database file
+func (this *SqliteWriter) Bytes() ([]byte, error) {
+	// schema table is built last to know root pages of tables
+	cells := make([]*sqliteCell, 0, len(this.tables))
+	for i, table := range this.tables {
+		rowid := int64(i + 1)
+		record := this.encodeRecord([]any{"table",
+			table.name, table.name, int64(table.rootPage), table.sql})
+		cells = append(cells, &sqliteCell{
+			this.encodeLeafCell(rowid, record), rowid, 0})
+	}
+	if _, err := this.buildTree(cells, 1); err != nil {
+		return nil, fmt.Errorf("schema table %s", err.Error())
+	}
+
+	this.writeFileHeader()
+
+	buffer := make([]byte, 0, len(this.pages)*sqlitePageSize)
+	for _, page := range this.pages {
+		buffer = append(buffer, page...)
+	}
+
+	return buffer, nil
+}
+
+func (this *SqliteWriter) writeFileHeader() {
+	h := this.pages[0]
+	copy(h[0:], "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(h[16:], sqlitePageSize)
+	// file format write and read version, legacy
+	h[18] = 1
+	h[19] = 1
+	// reserved space, payload fractions
+	h[20] = 0
+	h[21] = 64
+	h[22] = 32
+	h[23] = 32
+	// file change counter
+	binary.BigEndian.PutUint32(h[24:], 1)
+	// database size in pages
+	binary.BigEndian.PutUint32(h[28:], uint32(len(this.pages)))
+	// schema cookie and schema format
+	binary.BigEndian.PutUint32(h[40:], 1)
+	binary.BigEndian.PutUint32(h[44:], 4)
+	// text encoding utf-8
+	binary.BigEndian.PutUint32(h[56:], 1)
+	// version valid for, same as file change counter
+	binary.BigEndian.PutUint32(h[92:], 1)
+	// sqlite version number
+	binary.BigEndian.PutUint32(h[96:], 3046000)
+}
+
+func (this *SqliteWriter) newPage() int {
+	this.pages = append(this.pages, make([]byte, sqlitePageSize))
+	return len(this.pages)
+}
+
+// build b-tree from leaf cells bottom up, return the root page,
+// root is written to rootPage when it is not 0
+func (this *SqliteWriter) buildTree(
+	cells []*sqliteCell, rootPage int) (int, error) {
+
+	rootOffset := 0
+	if rootPage == 1 {
+		rootOffset = sqliteFileHeaderSize
+	}
+
+	leaf := true
+	for {
+		if this.getPageSize(rootOffset, cells, leaf) <= sqlitePageSize {
+			if rootPage == 0 {
+				rootPage = this.newPage()
+			}
+			this.writePage(rootPage, rootOffset, cells, leaf)
+			return rootPage, nil
+		}
+		if len(cells) <= 1 {
+			return 0, fmt.Errorf("row is too large")
+		}
+
+		// split cells into pages of the level, in cell index ranges
+		groupEnds := make([]int, 0)
+		start := 0
+		for i := 1; i <= len(cells); i++ {
+			if i < len(cells) &&
+				this.getPageSize(0, cells[start:i+1], leaf) <=
+					sqlitePageSize {
+				continue
+			}
+			groupEnds = append(groupEnds, i)
+			start = i
+		}
+		if len(groupEnds) == 1 {
+			// fits normal page but not the root page
+			groupEnds = []int{len(cells) / 2, len(cells)}
+		}
+		// interior page needs a cell besides the right-most pointer
+		n := len(groupEnds)
+		if leaf == false && n > 1 &&
+			groupEnds[n-1]-groupEnds[n-2] == 1 {
+			prevStart := 0
+			if n > 2 {
+				prevStart = groupEnds[n-3]
+			}
+			if groupEnds[n-2]-prevStart > 2 {
+				groupEnds[n-2] -= 1
+			}
+		}
+
+		groups := make([][]*sqliteCell, 0, len(groupEnds))
+		start = 0
+		for _, end := range groupEnds {
+			groups = append(groups, cells[start:end])
+			start = end
+		}
+
+		parentCells := make([]*sqliteCell, 0, len(groups))
+		for _, group := range groups {
+			page := this.newPage()
+			this.writePage(page, 0, group, leaf)
+			key := group[len(group)-1].key
+			parentCells = append(parentCells, &sqliteCell{
+				this.encodeInteriorCell(page, key), key, page})
+		}
+
+		cells = parentCells
+		leaf = false
+	}
+}
+
+// last cell of interior page is used as the right-most pointer
+func (this *SqliteWriter) getPageSize(
+	offset int, cells []*sqliteCell, leaf bool) int {
+
+	size := offset + 8
+	if leaf == false {
+		size += 4
+		cells = cells[:len(cells)-1]
+	}
+	for _, cell := range cells {
+		size += 2 + len(cell.data)
+	}
+
+	return size
+}
+
+func (this *SqliteWriter) writePage(
+	pageNumber int, offset int, cells []*sqliteCell, leaf bool) {
+
+	page := this.pages[pageNumber-1]
+	headerSize := 8
+	if leaf {
+		page[offset] = 0x0d
+	} else {
+		page[offset] = 0x05
+		headerSize = 12
+		binary.BigEndian.PutUint32(page[offset+8:],
+			uint32(cells[len(cells)-1].child))
+		cells = cells[:len(cells)-1]
+	}
+
+	contentStart := sqlitePageSize
+	for i, cell := range cells {
+		contentStart -= len(cell.data)
+		copy(page[contentStart:], cell.data)
+		binary.BigEndian.PutUint16(
+			page[offset+headerSize+i*2:], uint16(contentStart))
+	}
+	binary.BigEndian.PutUint16(page[offset+3:], uint16(len(cells)))
+	binary.BigEndian.PutUint16(page[offset+5:], uint16(contentStart))
+}
+
+func (this *SqliteWriter) encodeInteriorCell(child int, key int64) []byte {
+	data := binary.BigEndian.AppendUint32(nil, uint32(child))
+	return this.appendVarint(data, uint64(key))
+}
+
+// payload larger than the max local size is stored in overflow pages
+func (this *SqliteWriter) encodeLeafCell(rowid int64, payload []byte) []byte {
+	usableSize := sqlitePageSize
+	maxLocal := usableSize - 35
+	minLocal := (usableSize-12)*32/255 - 23
+
+	localSize := len(payload)
+	if localSize > maxLocal {
+		localSize = minLocal + (len(payload)-minLocal)%(usableSize-4)
+		if localSize > maxLocal {
+			localSize = minLocal
+		}
+	}
+
+	data := this.appendVarint(nil, uint64(len(payload)))
+	data = this.appendVarint(data, uint64(rowid))
+	data = append(data, payload[:localSize]...)
+	if localSize == len(payload) {
+		return data
+	}
+
+	// overflow page starts with the next overflow page number
+	overflow := payload[localSize:]
+	page := this.newPage()
+	data = binary.BigEndian.AppendUint32(data, uint32(page))
+	for {
+		n := min(len(overflow), usableSize-4)
+		copy(this.pages[page-1][4:], overflow[:n])
+		overflow = overflow[n:]
+		if len(overflow) == 0 {
+			break
+		}
+		nextPage := this.newPage()
+		binary.BigEndian.PutUint32(this.pages[page-1], uint32(nextPage))
+		page = nextPage
+	}
+
+	return data
+}
+
+func (this *SqliteWriter) encodeRecord(values []any) []byte {
+	header := make([]byte, 0)
+	body := make([]byte, 0)
+
+	for _, value := range values {
+		switch v := value.(type) {
+		case int64:
+			serialType, size := this.getIntSerialType(v)
+			header = this.appendVarint(header, serialType)
+			for i := size - 1; i >= 0; i-- {
+				body = append(body, byte(v>>(i*8)))
+			}
+		case string:
+			header = this.appendVarint(header, uint64(len(v)*2+13))
+			body = append(body, v...)
+		default:
+			header = this.appendVarint(header, 0)
+		}
+	}
+
+	// header size includes itself
+	headerSize := len(header) + 1
+	if len(this.appendVarint(nil, uint64(headerSize))) > 1 {
+		headerSize = len(header) + 2
+	}
+
+	record := this.appendVarint(nil, uint64(headerSize))
+	record = append(record, header...)
+	record = append(record, body...)
+
+	return record
+}
+
+// serial type and byte size of the integer
+func (this *SqliteWriter) getIntSerialType(v int64) (uint64, int) {
+	if v == 0 {
+		return 8, 0
+	} else if v == 1 {
+		return 9, 0
+	} else if v >= -1<<7 && v < 1<<7 {
+		return 1, 1
+	} else if v >= -1<<15 && v < 1<<15 {
+		return 2, 2
+	} else if v >= -1<<23 && v < 1<<23 {
+		return 3, 3
+	} else if v >= -1<<31 && v < 1<<31 {
+		return 4, 4
+	} else if v >= -1<<47 && v < 1<<47 {
+		return 5, 6
+	} else {
+		return 6, 8
+	}
+}
+
+// big-endian, 7 bits in each byte, the 9th byte has 8 bits
+func (this *SqliteWriter) appendVarint(buffer []byte, v uint64) []byte {
+	if v>>56 != 0 {
+		var b [9]byte
+		b[8] = byte(v)
+		v >>= 8
+		for i := 7; i >= 0; i-- {
+			b[i] = byte(v&0x7f) | 0x80
+			v >>= 7
+		}
+		return append(buffer, b[:]...)
+	}
+
+	var b [8]byte
+	n := 0
+	for {
+		b[n] = byte(v&0x7f) | 0x80
+		n++
+		v >>= 7
+		if v == 0 {
+			break
+		}
+	}
+	b[0] &= 0x7f
+	for i := n - 1; i >= 0; i-- {
+		buffer = append(buffer, b[i])
+	}
+
+	return buffer
+}
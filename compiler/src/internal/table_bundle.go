@@ -0,0 +1,196 @@
+package lib
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
+)
+
+// bundle file layout, see BundleDataExporter
+type TableBundle struct {
+	// crc32 of table schemas joined by new line
+	Fingerprint uint32
+	Entries     []*TableBundleEntry
+}
+
+type TableBundleEntry struct {
+	Name  string
+	Flags uint32
+	// size of the data before compressed
+	RawSize int
+	// stored data, compressed when flags has BundleEntryFlag_Deflate
+	Data []byte
+}
+
+func NewTableBundle(fingerprint uint32) *TableBundle {
+	newObj := new(TableBundle)
+	newObj.Fingerprint = fingerprint
+	newObj.Entries = make([]*TableBundleEntry, 0)
+
+	return newObj
+}
+
+func (this *TableBundle) Close() {
+	if this.Entries != nil {
+		clear(this.Entries)
+		this.Entries = nil
+	}
+}
+
+// fingerprint of the schemas of all tables of the reader filtered
+// descriptor, bundle of the reader packs the tables in the same order
+func GetTableBundleFingerprint(descriptor *TableDescriptor) uint32 {
+	schemas := make([]string, 0, len(descriptor.Tables))
+	for _, def := range descriptor.Tables {
+		schemas = append(schemas, GetTableBinarySchema(def))
+	}
+
+	return crc32.ChecksumIEEE([]byte(strings.Join(schemas, "\n")))
+}
+
+// create entry from the binary data of a table
+func NewTableBundleEntry(
+	name string, data []byte, compress bool) (*TableBundleEntry, error) {
+
+	newObj := new(TableBundleEntry)
+	newObj.Name = name
+	newObj.RawSize = len(data)
+	newObj.Data = data
+
+	if compress {
+		compressed, err := TableBundleDeflate(data)
+		if err != nil {
+			return nil, err
+		}
+		newObj.Flags |= BundleEntryFlag_Deflate
+		newObj.Data = compressed
+	}
+
+	return newObj, nil
+}
+
+// binary data of the table
+func (this *TableBundleEntry) RawData() ([]byte, error) {
+	if this.Flags&BundleEntryFlag_Deflate == 0 {
+		return this.Data, nil
+	}
+
+	reader := flate.NewReader(bytes.NewReader(this.Data))
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != this.RawSize {
+		return nil, fmt.Errorf(""+
+			"entry `%s` raw size %d is invalid, should be %d",
+			this.Name, len(data), this.RawSize)
+	}
+
+	return data, nil
+}
+
+func TableBundleDeflate(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	writer, err := flate.NewWriter(&out, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+func (this *TableBundle) Encode() []byte {
+	// header and table of contents
+	tocSize := 16
+	for _, entry := range this.Entries {
+		tocSize += 2 + len(entry.Name) + 20
+	}
+	buffer := make([]byte, 0, tocSize)
+	buffer = append(buffer, TableBundleMagic...)
+	buffer = binary.LittleEndian.AppendUint32(buffer, TableBundleVersion)
+	buffer = binary.LittleEndian.AppendUint32(buffer, this.Fingerprint)
+	buffer = binary.LittleEndian.AppendUint32(
+		buffer, uint32(len(this.Entries)))
+	offset := tocSize
+	for _, entry := range this.Entries {
+		buffer = binary.LittleEndian.AppendUint16(
+			buffer, uint16(len(entry.Name)))
+		buffer = append(buffer, entry.Name...)
+		buffer = binary.LittleEndian.AppendUint32(buffer, entry.Flags)
+		buffer = binary.LittleEndian.AppendUint32(buffer, uint32(offset))
+		buffer = binary.LittleEndian.AppendUint32(
+			buffer, uint32(len(entry.Data)))
+		buffer = binary.LittleEndian.AppendUint32(
+			buffer, uint32(entry.RawSize))
+		buffer = binary.LittleEndian.AppendUint32(
+			buffer, crc32.ChecksumIEEE(entry.Data))
+		offset += len(entry.Data)
+	}
+
+	// entry data
+	for _, entry := range this.Entries {
+		buffer = append(buffer, entry.Data...)
+	}
+
+	return buffer
+}
+
+// entry data is checked by crc32, raw data is not inflated
+func DecodeTableBundle(data []byte) (*TableBundle, error) {
+	if len(data) < 16 || string(data[0:4]) != TableBundleMagic {
+		return nil, fmt.Errorf("bundle magic is invalid")
+	}
+	version := binary.LittleEndian.Uint32(data[4:])
+	if version != TableBundleVersion {
+		return nil, fmt.Errorf("bundle version %d is not supported",
+			version)
+	}
+
+	bundle := NewTableBundle(binary.LittleEndian.Uint32(data[8:]))
+	entryCount := int(binary.LittleEndian.Uint32(data[12:]))
+	pos := 16
+
+	for range entryCount {
+		if pos+2 > len(data) {
+			return nil, fmt.Errorf("bundle toc is truncated")
+		}
+		nameLength := int(binary.LittleEndian.Uint16(data[pos:]))
+		pos += 2
+		if pos+nameLength+20 > len(data) {
+			return nil, fmt.Errorf("bundle toc is truncated")
+		}
+		entry := new(TableBundleEntry)
+		entry.Name = string(data[pos : pos+nameLength])
+		pos += nameLength
+		entry.Flags = binary.LittleEndian.Uint32(data[pos:])
+		offset := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		size := int(binary.LittleEndian.Uint32(data[pos+8:]))
+		entry.RawSize = int(binary.LittleEndian.Uint32(data[pos+12:]))
+		checksum := binary.LittleEndian.Uint32(data[pos+16:])
+		pos += 20
+
+		if offset > len(data) || size > len(data)-offset {
+			return nil, fmt.Errorf("entry `%s` is out of range",
+				entry.Name)
+		}
+		entry.Data = data[offset : offset+size]
+		if crc32.ChecksumIEEE(entry.Data) != checksum {
+			return nil, fmt.Errorf("entry `%s` crc32 mismatch",
+				entry.Name)
+		}
+		bundle.Entries = append(bundle.Entries, entry)
+	}
+
+	return bundle, nil
+}
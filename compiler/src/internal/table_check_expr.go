@@ -0,0 +1,843 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// row check expression of `check` node:
+//   literal    -> 123, 'text', "text", true, false
+//   column     -> column name of the table
+//   field      -> struct_value.field_name
+//   index      -> list_value[0]
+//   function   -> len(list_or_string)
+//   unary      -> !, not, -
+//   arithmetic -> *, /, %, +, - (+ also joins strings)
+//   compare    -> ==, !=, <, <=, >, >=
+//   logical    -> &&, and, ||, or
+// `<` and `&` must be written as `&lt;` and `&amp;` in define file,
+// so `and`, `or` and `not` are provided as keywords
+
+// returned when expression uses a column or field
+// whose type error is already reported and has no other error,
+// the expression is still parsed to find syntax errors
+var errTableCheckExprInvalidType = errors.New("invalid type")
+
+type tableCheckExprKind int
+
+const (
+	tableCheckExprKind_None tableCheckExprKind = iota
+	tableCheckExprKind_Int
+	tableCheckExprKind_String
+	tableCheckExprKind_Bool
+	tableCheckExprKind_Struct
+	tableCheckExprKind_List
+	// column or field whose type error is already reported
+	tableCheckExprKind_Invalid
+)
+
+type tableCheckExprType struct {
+	kind tableCheckExprKind
+	// struct define when kind is struct
+	structDef *StructDef
+	// item type when kind is list
+	itemType *tableCheckExprType
+}
+
+var g_tableCheckExprIntType = &tableCheckExprType{
+	kind: tableCheckExprKind_Int}
+var g_tableCheckExprStringType = &tableCheckExprType{
+	kind: tableCheckExprKind_String}
+var g_tableCheckExprBoolType = &tableCheckExprType{
+	kind: tableCheckExprKind_Bool}
+var g_tableCheckExprInvalidType = &tableCheckExprType{
+	kind: tableCheckExprKind_Invalid}
+
+func (this *tableCheckExprType) String() string {
+	if this.kind == tableCheckExprKind_Int {
+		return "int"
+	} else if this.kind == tableCheckExprKind_String {
+		return "string"
+	} else if this.kind == tableCheckExprKind_Bool {
+		return "bool"
+	} else if this.kind == tableCheckExprKind_Struct {
+		return this.structDef.Name
+	} else if this.kind == tableCheckExprKind_List {
+		return "list{" + this.itemType.String() + "}"
+	} else if this.kind == tableCheckExprKind_Invalid {
+		return "invalid"
+	} else {
+		return "none"
+	}
+}
+
+// ----------------------------------------------------------------------------
+type tableCheckExprTokenType int
+
+const (
+	tableCheckExprTokenType_None tableCheckExprTokenType = iota
+	tableCheckExprTokenType_Ident
+	tableCheckExprTokenType_Int
+	tableCheckExprTokenType_String
+	tableCheckExprTokenType_Operator
+	tableCheckExprTokenType_End
+)
+
+type tableCheckExprToken struct {
+	tokenType tableCheckExprTokenType
+	text      string
+	// position in expression text, starts from 1
+	position int
+}
+
+// longer operators first
+var g_tableCheckExprOperators = []string{
+	"==", "!=", "<=", ">=", "&&", "||",
+	"<", ">", "!", "+", "-", "*", "/", "%",
+	"(", ")", "[", "]", ".", ",",
+}
+
+func tokenizeTableCheckExpr(text string) ([]*tableCheckExprToken, error) {
+	tokens := make([]*tableCheckExprToken, 0)
+
+	i := 0
+	for i < len(text) {
+		c := text[i]
+		start := i
+
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+			i++
+			continue
+		}
+
+		if c == '_' ||
+			(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			for i < len(text) && (text[i] == '_' ||
+				(text[i] >= 'a' && text[i] <= 'z') ||
+				(text[i] >= 'A' && text[i] <= 'Z') ||
+				(text[i] >= '0' && text[i] <= '9')) {
+				i++
+			}
+			tokens = append(tokens, &tableCheckExprToken{
+				tableCheckExprTokenType_Ident, text[start:i], start + 1})
+			continue
+		}
+
+		if c >= '0' && c <= '9' {
+			for i < len(text) && text[i] >= '0' && text[i] <= '9' {
+				i++
+			}
+			tokens = append(tokens, &tableCheckExprToken{
+				tableCheckExprTokenType_Int, text[start:i], start + 1})
+			continue
+		}
+
+		if c == '\'' || c == '"' {
+			end := strings.IndexByte(text[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf(
+					"string at position %d is not closed", start+1)
+			}
+			i += end + 2
+			tokens = append(tokens, &tableCheckExprToken{
+				tableCheckExprTokenType_String,
+				text[start+1 : i-1], start + 1})
+			continue
+		}
+
+		found := false
+		for _, op := range g_tableCheckExprOperators {
+			if strings.HasPrefix(text[i:], op) {
+				i += len(op)
+				tokens = append(tokens, &tableCheckExprToken{
+					tableCheckExprTokenType_Operator, op, start + 1})
+				found = true
+				break
+			}
+		}
+		if found == false {
+			return nil, fmt.Errorf(
+				"character `%c` at position %d is invalid",
+				c, start+1)
+		}
+	}
+
+	tokens = append(tokens, &tableCheckExprToken{
+		tableCheckExprTokenType_End, "", len(text) + 1})
+
+	return tokens, nil
+}
+
+// ----------------------------------------------------------------------------
+type tableCheckExprOp int
+
+const (
+	tableCheckExprOp_None tableCheckExprOp = iota
+	tableCheckExprOp_Literal
+	tableCheckExprOp_Column
+	tableCheckExprOp_Field
+	tableCheckExprOp_Index
+	tableCheckExprOp_Len
+	tableCheckExprOp_Not
+	tableCheckExprOp_Neg
+	tableCheckExprOp_Add
+	tableCheckExprOp_Sub
+	tableCheckExprOp_Mul
+	tableCheckExprOp_Div
+	tableCheckExprOp_Mod
+	tableCheckExprOp_Eq
+	tableCheckExprOp_Ne
+	tableCheckExprOp_Lt
+	tableCheckExprOp_Le
+	tableCheckExprOp_Gt
+	tableCheckExprOp_Ge
+	tableCheckExprOp_And
+	tableCheckExprOp_Or
+)
+
+var g_tableCheckExprBinaryOps = map[string]tableCheckExprOp{
+	"+":   tableCheckExprOp_Add,
+	"-":   tableCheckExprOp_Sub,
+	"*":   tableCheckExprOp_Mul,
+	"/":   tableCheckExprOp_Div,
+	"%":   tableCheckExprOp_Mod,
+	"==":  tableCheckExprOp_Eq,
+	"!=":  tableCheckExprOp_Ne,
+	"<":   tableCheckExprOp_Lt,
+	"<=":  tableCheckExprOp_Le,
+	">":   tableCheckExprOp_Gt,
+	">=":  tableCheckExprOp_Ge,
+	"&&":  tableCheckExprOp_And,
+	"and": tableCheckExprOp_And,
+	"||":  tableCheckExprOp_Or,
+	"or":  tableCheckExprOp_Or,
+}
+
+type tableCheckExprNode struct {
+	op        tableCheckExprOp
+	valueType *tableCheckExprType
+	// value of literal
+	value any
+	// column define of column
+	columnDef *TableColumnDef
+	// struct field index of field
+	fieldIndex int
+	// operands
+	args []*tableCheckExprNode
+}
+
+// ----------------------------------------------------------------------------
+type TableCheckExpr struct {
+	// expression as written in define file
+	Text string
+	// columns used by the expression, in first use order
+	Columns []*TableColumnDef
+
+	root *tableCheckExprNode
+}
+
+// compile expression over columns of the table,
+// the expression must be `bool` type
+func CompileTableCheckExpr(
+	tableDef *TableDef, text string) (*TableCheckExpr, error) {
+
+	tokens, err := tokenizeTableCheckExpr(text)
+	if err != nil {
+		return nil, err
+	}
+
+	compiler := &tableCheckExprCompiler{
+		tableDef: tableDef,
+		tokens:   tokens,
+		expr: &TableCheckExpr{
+			Text:    text,
+			Columns: make([]*TableColumnDef, 0),
+		},
+	}
+
+	root, err := compiler.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if token := compiler.peek(); token.tokenType !=
+		tableCheckExprTokenType_End {
+		return nil, fmt.Errorf(
+			"unexpected `%s` at position %d", token.text, token.position)
+	}
+	if compiler.hasInvalidType {
+		return nil, errTableCheckExprInvalidType
+	}
+	if root.valueType.kind != tableCheckExprKind_Bool {
+		return nil, fmt.Errorf(
+			"expression type `%s` is invalid, should be `bool`",
+			root.valueType.String())
+	}
+	compiler.expr.root = root
+
+	return compiler.expr, nil
+}
+
+// evaluate expression with parsed column values,
+// values are in the form returned by ParseTableColumnValue
+func (this *TableCheckExpr) Evaluate(
+	values map[*TableColumnDef]any) (bool, error) {
+
+	v, err := this.evaluateNode(this.root, values)
+	if err != nil {
+		return false, err
+	}
+
+	return v.(bool), nil
+}
+
+// int values are evaluated as int64
+func (this *TableCheckExpr) toExprValue(v any) any {
+	if i, ok := v.(int32); ok {
+		return int64(i)
+	}
+
+	return v
+}
+
+func (this *TableCheckExpr) evaluateNode(
+	node *tableCheckExprNode, values map[*TableColumnDef]any) (any, error) {
+
+	if node.op == tableCheckExprOp_Literal {
+		return node.value, nil
+	} else if node.op == tableCheckExprOp_Column {
+		v, ok := values[node.columnDef]
+		if ok == false {
+			return nil, fmt.Errorf(
+				"column `%s` value is missing", node.columnDef.Name)
+		}
+		return this.toExprValue(v), nil
+	}
+
+	// logical operators only evaluate the right side when needed
+	if node.op == tableCheckExprOp_And || node.op == tableCheckExprOp_Or {
+		left, err := this.evaluateNode(node.args[0], values)
+		if err != nil {
+			return nil, err
+		}
+		if left.(bool) == (node.op == tableCheckExprOp_Or) {
+			return left, nil
+		}
+		return this.evaluateNode(node.args[1], values)
+	}
+
+	args := make([]any, 0, len(node.args))
+	for _, argNode := range node.args {
+		v, err := this.evaluateNode(argNode, values)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+
+	if node.op == tableCheckExprOp_Field {
+		return this.toExprValue(args[0].([]any)[node.fieldIndex]), nil
+	} else if node.op == tableCheckExprOp_Index {
+		items := args[0].([]any)
+		index := args[1].(int64)
+		if index < 0 || index >= int64(len(items)) {
+			return nil, fmt.Errorf(
+				"list index %d is out of range, list length is %d",
+				index, len(items))
+		}
+		return this.toExprValue(items[index]), nil
+	} else if node.op == tableCheckExprOp_Len {
+		if s, ok := args[0].(string); ok {
+			return int64(utf8.RuneCountInString(s)), nil
+		}
+		return int64(len(args[0].([]any))), nil
+	} else if node.op == tableCheckExprOp_Not {
+		return args[0].(bool) == false, nil
+	} else if node.op == tableCheckExprOp_Neg {
+		return -args[0].(int64), nil
+	} else if node.op == tableCheckExprOp_Eq {
+		return args[0] == args[1], nil
+	} else if node.op == tableCheckExprOp_Ne {
+		return args[0] != args[1], nil
+	}
+
+	if node.op == tableCheckExprOp_Add {
+		if s, ok := args[0].(string); ok {
+			return s + args[1].(string), nil
+		}
+	}
+
+	if l, ok := args[0].(string); ok {
+		r := args[1].(string)
+		if node.op == tableCheckExprOp_Lt {
+			return l < r, nil
+		} else if node.op == tableCheckExprOp_Le {
+			return l <= r, nil
+		} else if node.op == tableCheckExprOp_Gt {
+			return l > r, nil
+		} else {
+			return l >= r, nil
+		}
+	}
+
+	l := args[0].(int64)
+	r := args[1].(int64)
+	if node.op == tableCheckExprOp_Add {
+		return l + r, nil
+	} else if node.op == tableCheckExprOp_Sub {
+		return l - r, nil
+	} else if node.op == tableCheckExprOp_Mul {
+		return l * r, nil
+	} else if node.op == tableCheckExprOp_Div ||
+		node.op == tableCheckExprOp_Mod {
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		if node.op == tableCheckExprOp_Div {
+			return l / r, nil
+		}
+		return l % r, nil
+	} else if node.op == tableCheckExprOp_Lt {
+		return l < r, nil
+	} else if node.op == tableCheckExprOp_Le {
+		return l <= r, nil
+	} else if node.op == tableCheckExprOp_Gt {
+		return l > r, nil
+	} else {
+		return l >= r, nil
+	}
+}
+
+// ----------------------------------------------------------------------------
+type tableCheckExprCompiler struct {
+	tableDef *TableDef
+	tokens   []*tableCheckExprToken
+	index    int
+	expr     *TableCheckExpr
+	// type of a used column or field is invalid
+	hasInvalidType bool
+}
+
+func (this *tableCheckExprCompiler) peek() *tableCheckExprToken {
+	return this.tokens[this.index]
+}
+
+func (this *tableCheckExprCompiler) next() *tableCheckExprToken {
+	token := this.tokens[this.index]
+	if token.tokenType != tableCheckExprTokenType_End {
+		this.index++
+	}
+
+	return token
+}
+
+func (this *tableCheckExprCompiler) isOperator(
+	token *tableCheckExprToken, text string) bool {
+
+	return token.tokenType == tableCheckExprTokenType_Operator &&
+		token.text == text
+}
+
+func (this *tableCheckExprCompiler) expectOperator(text string) error {
+	token := this.next()
+	if this.isOperator(token, text) == false {
+		return this.unexpectedTokenError(token, "`"+text+"`")
+	}
+
+	return nil
+}
+
+func (this *tableCheckExprCompiler) unexpectedTokenError(
+	token *tableCheckExprToken, expected string) error {
+
+	if token.tokenType == tableCheckExprTokenType_End {
+		return fmt.Errorf("expect %s at end of expression", expected)
+	}
+
+	return fmt.Errorf("expect %s at position %d, but got `%s`",
+		expected, token.position, token.text)
+}
+
+// binary operator precedence, higher binds tighter, 0 when not binary
+func (this *tableCheckExprCompiler) getPrecedence(
+	token *tableCheckExprToken) int {
+
+	if token.tokenType != tableCheckExprTokenType_Operator &&
+		token.tokenType != tableCheckExprTokenType_Ident {
+		return 0
+	}
+
+	op, ok := g_tableCheckExprBinaryOps[token.text]
+	if ok == false {
+		return 0
+	}
+	if op == tableCheckExprOp_Or {
+		return 1
+	} else if op == tableCheckExprOp_And {
+		return 2
+	} else if op >= tableCheckExprOp_Eq {
+		return 3
+	} else if op == tableCheckExprOp_Add || op == tableCheckExprOp_Sub {
+		return 4
+	} else {
+		return 5
+	}
+}
+
+// parse binary expression whose operators bind tighter than minPrecedence
+func (this *tableCheckExprCompiler) parseBinary(
+	minPrecedence int) (*tableCheckExprNode, error) {
+
+	left, err := this.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		token := this.peek()
+		precedence := this.getPrecedence(token)
+		if precedence == 0 || precedence <= minPrecedence {
+			break
+		}
+		this.next()
+
+		right, err := this.parseBinary(precedence)
+		if err != nil {
+			return nil, err
+		}
+		// compare operators do not chain
+		if precedence == 3 && this.getPrecedence(this.peek()) == 3 {
+			next := this.peek()
+			return nil, fmt.Errorf(
+				"compare operator `%s` at position %d can not be chained",
+				next.text, next.position)
+		}
+
+		left, err = this.newBinaryNode(token, left, right)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return left, nil
+}
+
+func (this *tableCheckExprCompiler) newBinaryNode(
+	token *tableCheckExprToken,
+	left *tableCheckExprNode,
+	right *tableCheckExprNode) (*tableCheckExprNode, error) {
+
+	op := g_tableCheckExprBinaryOps[token.text]
+	node := &tableCheckExprNode{
+		op:   op,
+		args: []*tableCheckExprNode{left, right},
+	}
+
+	leftKind := left.valueType.kind
+	rightKind := right.valueType.kind
+	typeOk := false
+	if op == tableCheckExprOp_And || op == tableCheckExprOp_Or {
+		typeOk = leftKind == tableCheckExprKind_Bool &&
+			rightKind == tableCheckExprKind_Bool
+		node.valueType = g_tableCheckExprBoolType
+	} else if op == tableCheckExprOp_Eq || op == tableCheckExprOp_Ne {
+		typeOk = leftKind == rightKind &&
+			(leftKind == tableCheckExprKind_Int ||
+				leftKind == tableCheckExprKind_String ||
+				leftKind == tableCheckExprKind_Bool)
+		node.valueType = g_tableCheckExprBoolType
+	} else if op >= tableCheckExprOp_Lt {
+		typeOk = leftKind == rightKind &&
+			(leftKind == tableCheckExprKind_Int ||
+				leftKind == tableCheckExprKind_String)
+		node.valueType = g_tableCheckExprBoolType
+	} else if op == tableCheckExprOp_Add &&
+		leftKind == tableCheckExprKind_String {
+		typeOk = rightKind == tableCheckExprKind_String
+		node.valueType = g_tableCheckExprStringType
+	} else {
+		typeOk = leftKind == tableCheckExprKind_Int &&
+			rightKind == tableCheckExprKind_Int
+		node.valueType = g_tableCheckExprIntType
+	}
+
+	if leftKind == tableCheckExprKind_Invalid ||
+		rightKind == tableCheckExprKind_Invalid {
+		// arithmetic result type depends on the operands
+		if op < tableCheckExprOp_Eq {
+			node.valueType = g_tableCheckExprInvalidType
+		}
+		return node, nil
+	}
+	if typeOk == false {
+		return nil, fmt.Errorf(
+			"operator `%s` at position %d "+
+				"can not be applied to `%s` and `%s`",
+			token.text, token.position,
+			left.valueType.String(), right.valueType.String())
+	}
+
+	return node, nil
+}
+
+func (this *tableCheckExprCompiler) parseUnary() (*tableCheckExprNode, error) {
+	token := this.peek()
+
+	op := tableCheckExprOp_None
+	if this.isOperator(token, "!") ||
+		(token.tokenType == tableCheckExprTokenType_Ident &&
+			token.text == "not") {
+		op = tableCheckExprOp_Not
+	} else if this.isOperator(token, "-") {
+		op = tableCheckExprOp_Neg
+	} else {
+		return this.parsePostfix()
+	}
+	this.next()
+
+	arg, err := this.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	node := &tableCheckExprNode{
+		op:   op,
+		args: []*tableCheckExprNode{arg},
+	}
+	if op == tableCheckExprOp_Not {
+		node.valueType = g_tableCheckExprBoolType
+	} else {
+		node.valueType = g_tableCheckExprIntType
+	}
+	if arg.valueType != node.valueType &&
+		arg.valueType.kind != tableCheckExprKind_Invalid {
+		return nil, fmt.Errorf(
+			"operator `%s` at position %d can not be applied to `%s`",
+			token.text, token.position, arg.valueType.String())
+	}
+
+	return node, nil
+}
+
+func (this *tableCheckExprCompiler) parsePostfix() (*tableCheckExprNode, error) {
+	node, err := this.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		token := this.peek()
+
+		if this.isOperator(token, ".") {
+			this.next()
+			if node.valueType.kind == tableCheckExprKind_Invalid {
+				nameToken := this.next()
+				if nameToken.tokenType != tableCheckExprTokenType_Ident {
+					return nil, this.unexpectedTokenError(
+						nameToken, "a field name")
+				}
+				continue
+			}
+			if node.valueType.kind != tableCheckExprKind_Struct {
+				return nil, fmt.Errorf(
+					"field access at position %d "+
+						"can not be applied to `%s`",
+					token.position, node.valueType.String())
+			}
+			nameToken := this.next()
+			if nameToken.tokenType != tableCheckExprTokenType_Ident {
+				return nil, this.unexpectedTokenError(
+					nameToken, "a field name")
+			}
+
+			structDef := node.valueType.structDef
+			fieldDef, ok := structDef.FieldNameIndex[nameToken.text]
+			if ok == false {
+				return nil, fmt.Errorf(
+					"struct `%s` field `%s` is not defined",
+					structDef.Name, nameToken.text)
+			}
+			fieldNode := &tableCheckExprNode{
+				op:         tableCheckExprOp_Field,
+				fieldIndex: slices.Index(structDef.Fields, fieldDef),
+				args:       []*tableCheckExprNode{node},
+			}
+			if fieldDef.Type == StructFieldType_Int {
+				fieldNode.valueType = g_tableCheckExprIntType
+			} else if fieldDef.Type == StructFieldType_String {
+				fieldNode.valueType = g_tableCheckExprStringType
+			} else {
+				this.hasInvalidType = true
+				fieldNode.valueType = g_tableCheckExprInvalidType
+			}
+			node = fieldNode
+		} else if this.isOperator(token, "[") {
+			this.next()
+			isInvalid := node.valueType.kind == tableCheckExprKind_Invalid
+			if isInvalid == false &&
+				node.valueType.kind != tableCheckExprKind_List {
+				return nil, fmt.Errorf(
+					"index at position %d can not be applied to `%s`",
+					token.position, node.valueType.String())
+			}
+			indexNode, err := this.parseBinary(0)
+			if err != nil {
+				return nil, err
+			}
+			if indexNode.valueType.kind != tableCheckExprKind_Int &&
+				indexNode.valueType.kind != tableCheckExprKind_Invalid {
+				return nil, fmt.Errorf(
+					"index at position %d type `%s` is invalid, "+
+						"should be `int`",
+					token.position, indexNode.valueType.String())
+			}
+			if err := this.expectOperator("]"); err != nil {
+				return nil, err
+			}
+			if isInvalid {
+				continue
+			}
+			node = &tableCheckExprNode{
+				op:        tableCheckExprOp_Index,
+				valueType: node.valueType.itemType,
+				args:      []*tableCheckExprNode{node, indexNode},
+			}
+		} else {
+			break
+		}
+	}
+
+	return node, nil
+}
+
+func (this *tableCheckExprCompiler) parsePrimary() (*tableCheckExprNode, error) {
+	token := this.next()
+
+	if token.tokenType == tableCheckExprTokenType_Int {
+		v, err := strconv.ParseInt(token.text, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"int value `%s` at position %d is out of range",
+				token.text, token.position)
+		}
+		return &tableCheckExprNode{
+			op:        tableCheckExprOp_Literal,
+			valueType: g_tableCheckExprIntType,
+			value:     v,
+		}, nil
+	} else if token.tokenType == tableCheckExprTokenType_String {
+		return &tableCheckExprNode{
+			op:        tableCheckExprOp_Literal,
+			valueType: g_tableCheckExprStringType,
+			value:     token.text,
+		}, nil
+	} else if this.isOperator(token, "(") {
+		node, err := this.parseBinary(0)
+		if err != nil {
+			return nil, err
+		}
+		if err := this.expectOperator(")"); err != nil {
+			return nil, err
+		}
+		return node, nil
+	} else if token.tokenType != tableCheckExprTokenType_Ident {
+		return nil, this.unexpectedTokenError(token, "a value")
+	}
+
+	if token.text == "true" || token.text == "false" {
+		return &tableCheckExprNode{
+			op:        tableCheckExprOp_Literal,
+			valueType: g_tableCheckExprBoolType,
+			value:     token.text == "true",
+		}, nil
+	}
+
+	if this.isOperator(this.peek(), "(") {
+		return this.parseCall(token)
+	}
+
+	columnDef, ok := this.tableDef.ColumnNameIndex[token.text]
+	if ok == false {
+		return nil, fmt.Errorf(
+			"column `%s` at position %d is not defined",
+			token.text, token.position)
+	}
+	valueType := this.getColumnType(columnDef)
+	if slices.Contains(this.expr.Columns, columnDef) == false {
+		this.expr.Columns = append(this.expr.Columns, columnDef)
+	}
+
+	return &tableCheckExprNode{
+		op:        tableCheckExprOp_Column,
+		valueType: valueType,
+		columnDef: columnDef,
+	}, nil
+}
+
+func (this *tableCheckExprCompiler) parseCall(
+	nameToken *tableCheckExprToken) (*tableCheckExprNode, error) {
+
+	if nameToken.text != "len" {
+		return nil, fmt.Errorf(
+			"function `%s` at position %d is not defined",
+			nameToken.text, nameToken.position)
+	}
+
+	this.next()
+	arg, err := this.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if err := this.expectOperator(")"); err != nil {
+		return nil, err
+	}
+	if arg.valueType.kind != tableCheckExprKind_List &&
+		arg.valueType.kind != tableCheckExprKind_String &&
+		arg.valueType.kind != tableCheckExprKind_Invalid {
+		return nil, fmt.Errorf(
+			"function `len` at position %d can not be applied to `%s`",
+			nameToken.position, arg.valueType.String())
+	}
+
+	return &tableCheckExprNode{
+		op:        tableCheckExprOp_Len,
+		valueType: g_tableCheckExprIntType,
+		args:      []*tableCheckExprNode{arg},
+	}, nil
+}
+
+func (this *tableCheckExprCompiler) getColumnType(
+	columnDef *TableColumnDef) *tableCheckExprType {
+
+	if columnDef.Type == TableColumnType_List {
+		return &tableCheckExprType{
+			kind: tableCheckExprKind_List,
+			itemType: this.getValueType(
+				columnDef.ListType, columnDef.RefStructDef),
+		}
+	}
+
+	return this.getValueType(columnDef.Type, columnDef.RefStructDef)
+}
+
+func (this *tableCheckExprCompiler) getValueType(
+	columnType TableColumnType,
+	structDef *StructDef) *tableCheckExprType {
+
+	if columnType == TableColumnType_Int {
+		return g_tableCheckExprIntType
+	} else if columnType == TableColumnType_String {
+		return g_tableCheckExprStringType
+	} else if columnType == TableColumnType_Struct {
+		return &tableCheckExprType{
+			kind:      tableCheckExprKind_Struct,
+			structDef: structDef,
+		}
+	} else {
+		this.hasInvalidType = true
+		return g_tableCheckExprInvalidType
+	}
+}
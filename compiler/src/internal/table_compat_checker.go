@@ -0,0 +1,287 @@
+package lib
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// report define changes that old generated code can not read
+// the new data with, changes are warnings, or errors in strict mode
+type TableCompatChecker struct {
+	Diagnostics *DiagnosticList
+
+	strict bool
+}
+
+func NewTableCompatChecker(strict bool) *TableCompatChecker {
+	newObj := new(TableCompatChecker)
+	newObj.Diagnostics = NewDiagnosticList()
+	newObj.strict = strict
+
+	return newObj
+}
+
+func (this *TableCompatChecker) Close() {
+	if this.Diagnostics != nil {
+		this.Diagnostics.Close()
+		this.Diagnostics = nil
+	}
+}
+
+func (this *TableCompatChecker) addChange(code string,
+	filePath string, lineNumber int, format string, args ...any) {
+
+	if this.strict {
+		this.Diagnostics.AddError(code,
+			filePath, lineNumber, 0, format, args...)
+	} else {
+		this.Diagnostics.AddWarning(code,
+			filePath, lineNumber, 0, format, args...)
+	}
+}
+
+// reader names of the define in define order
+func (this *TableCompatChecker) GetReaderNames(
+	descriptor *TableDescriptor) []string {
+
+	readers := make([]*ReaderDef, 0, len(descriptor.Readers))
+	for _, def := range descriptor.Readers {
+		readers = append(readers, def)
+	}
+	slices.SortFunc(readers, func(a, b *ReaderDef) int {
+		return a.LineNumber - b.LineNumber
+	})
+
+	names := make([]string, 0, len(readers))
+	for _, def := range readers {
+		names = append(names, def.Name)
+	}
+
+	return names
+}
+
+// check the readers of old define still exist in new define
+func (this *TableCompatChecker) CheckReaders(
+	oldDescriptor *TableDescriptor, newDescriptor *TableDescriptor,
+	readers []string) {
+
+	for _, name := range readers {
+		if _, ok := newDescriptor.Readers[name]; ok {
+			continue
+		}
+		def := oldDescriptor.Readers[name]
+		this.addChange("reader-removed",
+			oldDescriptor.FilePath, def.LineNumber,
+			"reader `%s` is removed", name)
+	}
+}
+
+// compare tables of the descriptors filtered by the same reader,
+// reader is empty when descriptors are not filtered
+func (this *TableCompatChecker) CheckTables(
+	oldDescriptor *TableDescriptor, newDescriptor *TableDescriptor,
+	reader string) {
+
+	forReader := ""
+	if reader != "" {
+		forReader = fmt.Sprintf(" for reader `%s`", reader)
+	}
+
+	for _, oldDef := range oldDescriptor.Tables {
+		newDef, ok := newDescriptor.TableNameIndex[oldDef.Name]
+		if ok == false {
+			this.addChange("table-removed",
+				oldDescriptor.FilePath, oldDef.LineNumber,
+				"table `%s` is removed%s", oldDef.Name, forReader)
+			continue
+		}
+		this.checkTable(newDescriptor.FilePath,
+			oldDef, newDef, forReader)
+	}
+}
+
+func (this *TableCompatChecker) checkTable(filePath string,
+	oldDef *TableDef, newDef *TableDef, forReader string) {
+
+	// check file
+	if oldDef.FileName != newDef.FileName {
+		this.addChange("file-changed", filePath, newDef.LineNumber,
+			"table `%s` data file is changed from `%s` to `%s`%s",
+			newDef.Name, oldDef.FileName, newDef.FileName, forReader)
+	}
+
+	// check kind
+	if oldDef.TableKind != newDef.TableKind {
+		this.addChange("kind-changed", filePath, newDef.LineNumber,
+			"table `%s` kind is changed from `%s` to `%s`%s",
+			newDef.Name,
+			this.getTableKindName(oldDef.TableKind),
+			this.getTableKindName(newDef.TableKind), forReader)
+		return
+	}
+
+	// check key
+	if oldDef.TableKind == TableKind_Normal {
+		oldKey := this.getTableKeyText(oldDef)
+		newKey := this.getTableKeyText(newDef)
+		if oldKey != newKey {
+			this.addChange("key-changed", filePath, newDef.LineNumber,
+				"table `%s` key is changed from `%s` to `%s`%s",
+				newDef.Name, oldKey, newKey, forReader)
+		}
+	}
+
+	this.checkColumns(filePath, oldDef, newDef, forReader)
+}
+
+func (this *TableCompatChecker) checkColumns(filePath string,
+	oldDef *TableDef, newDef *TableDef, forReader string) {
+
+	// renamed column is a removed column whose position
+	// is taken by an added column
+	renamedColumns := make(map[*TableColumnDef]*TableColumnDef)
+
+	for i, oldColumnDef := range oldDef.Columns {
+		if _, ok := newDef.ColumnNameIndex[oldColumnDef.Name]; ok {
+			continue
+		}
+
+		if oldDef.TableKind == TableKind_Normal &&
+			i < len(newDef.Columns) {
+			newColumnDef := newDef.Columns[i]
+			_, ok := oldDef.ColumnNameIndex[newColumnDef.Name]
+			if ok == false {
+				renamedColumns[newColumnDef] = oldColumnDef
+				this.addChange("column-renamed",
+					filePath, newColumnDef.LineNumber,
+					"table `%s` column `%s` is renamed to `%s`%s",
+					newDef.Name, oldColumnDef.Name, newColumnDef.Name,
+					forReader)
+				continue
+			}
+		}
+
+		this.addChange("column-removed", filePath, newDef.LineNumber,
+			"table `%s` column `%s` is removed%s",
+			newDef.Name, oldColumnDef.Name, forReader)
+	}
+
+	for _, newColumnDef := range newDef.Columns {
+		oldColumnDef, ok := oldDef.ColumnNameIndex[newColumnDef.Name]
+		if ok == false {
+			oldColumnDef, ok = renamedColumns[newColumnDef]
+			if ok == false {
+				this.addChange("column-added",
+					filePath, newColumnDef.LineNumber,
+					"table `%s` column `%s` is added%s",
+					newDef.Name, newColumnDef.Name, forReader)
+				continue
+			}
+		}
+
+		oldShape := this.getColumnShape(oldColumnDef)
+		newShape := this.getColumnShape(newColumnDef)
+		if oldShape == newShape {
+			continue
+		}
+		if oldColumnDef.TypeName != newColumnDef.TypeName {
+			this.addChange("column-type-changed",
+				filePath, newColumnDef.LineNumber,
+				"table `%s` column `%s` type is changed "+
+					"from `%s` to `%s`%s",
+				newDef.Name, newColumnDef.Name,
+				oldColumnDef.TypeName, newColumnDef.TypeName, forReader)
+		} else {
+			this.addChange("column-type-changed",
+				filePath, newColumnDef.LineNumber,
+				"table `%s` column `%s` struct `%s` fields are changed "+
+					"from `%s` to `%s`%s",
+				newDef.Name, newColumnDef.Name,
+				newColumnDef.RefStructDef.Name,
+				oldShape, newShape, forReader)
+		}
+	}
+
+	// config table is read by name, so column order does not matter
+	if oldDef.TableKind != TableKind_Normal {
+		return
+	}
+
+	// check order of columns in both tables
+	oldNames := make([]string, 0, len(oldDef.Columns))
+	for _, def := range oldDef.Columns {
+		if _, ok := newDef.ColumnNameIndex[def.Name]; ok {
+			oldNames = append(oldNames, def.Name)
+		}
+	}
+	newNames := make([]string, 0, len(newDef.Columns))
+	for _, def := range newDef.Columns {
+		if _, ok := oldDef.ColumnNameIndex[def.Name]; ok {
+			newNames = append(newNames, def.Name)
+		}
+	}
+	if slices.Equal(oldNames, newNames) == false {
+		this.addChange("column-moved", filePath, newDef.LineNumber,
+			"table `%s` column order is changed from `%s` to `%s`%s",
+			newDef.Name, strings.Join(oldNames, "|"),
+			strings.Join(newNames, "|"), forReader)
+	}
+}
+
+func (this *TableCompatChecker) getTableKindName(kind TableKind) string {
+	if kind == TableKind_Config {
+		return "config"
+	} else {
+		return "normal"
+	}
+}
+
+// `key` or `setkey` attribute of the table
+func (this *TableCompatChecker) getTableKeyText(def *TableDef) string {
+	if def.TableKey == nil {
+		return ""
+	}
+
+	if def.TableKeyType == TableKeyType_SetKey {
+		return "setkey=" + def.TableKey.Name
+	} else {
+		return "key=" + def.TableKey.Name
+	}
+}
+
+// column type as data file layout,
+// struct is written as its field types, so renaming does not matter
+func (this *TableCompatChecker) getColumnShape(def *TableColumnDef) string {
+	if def.Type == TableColumnType_List {
+		return "list{" +
+			this.getValueShape(def.ListType, def.RefStructDef) + "}"
+	}
+
+	return this.getValueShape(def.Type, def.RefStructDef)
+}
+
+func (this *TableCompatChecker) getValueShape(
+	columnType TableColumnType, structDef *StructDef) string {
+
+	if columnType == TableColumnType_Int {
+		return "int"
+	} else if columnType == TableColumnType_String {
+		return "string"
+	} else if columnType != TableColumnType_Struct {
+		return "none"
+	}
+
+	fieldShapes := make([]string, 0, len(structDef.Fields))
+	for _, def := range structDef.Fields {
+		if def.Type == StructFieldType_Int {
+			fieldShapes = append(fieldShapes, "int")
+		} else if def.Type == StructFieldType_String {
+			fieldShapes = append(fieldShapes, "string")
+		} else {
+			fieldShapes = append(fieldShapes, "none")
+		}
+	}
+
+	return strings.Join(fieldShapes, ";")
+}
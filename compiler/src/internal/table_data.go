@@ -0,0 +1,386 @@
+package lib
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type TableDataRow struct {
+	// source file name, relative to input directory
+	FileName string
+	// source file path, for diagnostics
+	FilePath string
+	// line number in source file
+	LineNumber int
+
+	Columns []string
+}
+
+func NewTableDataRow(fileName string, filePath string,
+	lineNumber int, columns []string) *TableDataRow {
+
+	newObj := new(TableDataRow)
+	newObj.FileName = fileName
+	newObj.FilePath = filePath
+	newObj.LineNumber = lineNumber
+	newObj.Columns = columns
+
+	return newObj
+}
+
+// ----------------------------------------------------------------------------
+type TableData struct {
+	// link to table define
+	TableDefRef *TableDef
+
+	// column names of data file
+	ColumnNames []string
+	// comment line of the first source file
+	CommentLine *TableDataRow
+	// name line of the first source file
+	NameLine *TableDataRow
+	// data lines of all source files, in source file order
+	Rows []*TableDataRow
+}
+
+func NewTableData(tableDefRef *TableDef) *TableData {
+	newObj := new(TableData)
+	newObj.TableDefRef = tableDefRef
+	newObj.ColumnNames = make([]string, 0)
+	newObj.Rows = make([]*TableDataRow, 0)
+
+	if tableDefRef.TableKind == TableKind_Config {
+		newObj.ColumnNames = append(
+			newObj.ColumnNames, ConfigTableColumnNames...)
+	} else {
+		for _, def := range tableDefRef.Columns {
+			newObj.ColumnNames = append(newObj.ColumnNames, def.Name)
+		}
+	}
+
+	return newObj
+}
+
+func (this *TableData) Close() {
+	if this.Rows != nil {
+		clear(this.Rows)
+		this.Rows = nil
+	}
+	this.NameLine = nil
+	this.CommentLine = nil
+	this.ColumnNames = nil
+	this.TableDefRef = nil
+}
+
+// key column index of data file, config table uses the name column
+func (this *TableData) KeyColumnIndex() int {
+	if this.TableDefRef.TableKind == TableKind_Config {
+		return 0
+	} else {
+		return this.TableDefRef.TableKeyColumnIndex
+	}
+}
+
+// ----------------------------------------------------------------------------
+type TableDataReader struct {
+	Diagnostics *DiagnosticList
+
+	inputDir string
+}
+
+func NewTableDataReader(inputDir string) *TableDataReader {
+	newObj := new(TableDataReader)
+	newObj.Diagnostics = NewDiagnosticList()
+	newObj.inputDir = inputDir
+
+	return newObj
+}
+
+func (this *TableDataReader) Close() {
+	if this.Diagnostics != nil {
+		this.Diagnostics.Close()
+		this.Diagnostics = nil
+	}
+}
+
+// expand source file glob patterns of the table,
+// each file is returned only once in source file define order
+func (this *TableDataReader) GetSourceFileNames(
+	tableDef *TableDef) ([]string, bool) {
+
+	fileNames := make([]string, 0)
+
+	for _, pattern := range tableDef.SourceFileNames {
+		if strings.ContainsAny(pattern, "*?[") == false {
+			if slices.Contains(fileNames, pattern) == false {
+				fileNames = append(fileNames, pattern)
+			}
+			continue
+		}
+
+		patternPath := filepath.Join(this.inputDir, pattern)
+		matches, err := filepath.Glob(patternPath)
+		if err != nil {
+			this.Diagnostics.AddError("invalid-file-pattern", patternPath, 0, 0,
+				"input file pattern is invalid: %s", err.Error())
+			return nil, false
+		}
+		if len(matches) == 0 {
+			this.Diagnostics.AddError("no-matching-file", patternPath, 0, 0,
+				"input file pattern matches no file")
+			return nil, false
+		}
+		for _, match := range matches {
+			fileName, err := filepath.Rel(this.inputDir, match)
+			if err != nil {
+				fileName = match
+			}
+			fileName = filepath.ToSlash(fileName)
+			if slices.Contains(fileNames, fileName) == false {
+				fileNames = append(fileNames, fileName)
+			}
+		}
+	}
+
+	return fileNames, true
+}
+
+// read all source files of the table,
+// lines with errors are reported and skipped,
+// return nil when no data can be read
+func (this *TableDataReader) ReadTable(tableDef *TableDef) *TableData {
+	fileNames, ok := this.GetSourceFileNames(tableDef)
+	if ok == false {
+		return nil
+	}
+
+	return this.readFiles(tableDef, fileNames)
+}
+
+// read the output file of cutter, which is named as the table file name
+func (this *TableDataReader) ReadCutTable(tableDef *TableDef) *TableData {
+	return this.readFiles(tableDef, []string{tableDef.FileName})
+}
+
+func (this *TableDataReader) readFiles(
+	tableDef *TableDef, fileNames []string) *TableData {
+
+	tableData := NewTableData(tableDef)
+
+	for _, fileName := range fileNames {
+		this.readFile(tableData, fileName)
+	}
+	if tableData.CommentLine == nil {
+		tableData.Close()
+		return nil
+	}
+
+	this.checkKeys(tableData)
+
+	return tableData
+}
+
+func (this *TableDataReader) readFile(
+	tableData *TableData, fileName string) bool {
+
+	// read input file
+	filePath := filepath.Join(this.inputDir, fileName)
+	fileBytes, err := UtilReadAllBytesShared(filePath)
+	if err != nil {
+		this.Diagnostics.AddError("read-file-failed", filePath, 0, 0,
+			"read file failed: %s", err.Error())
+		return false
+	}
+
+	// split lines
+	lines := strings.Split(string(fileBytes), "\r\n")
+	if lines[len(lines)-1] != "" {
+		this.Diagnostics.AddError("missing-line-ending",
+			filePath, len(lines), 0,
+			"file line ending is required")
+		return false
+	}
+
+	lineCount := len(lines) - 1
+	if lineCount < 2 {
+		this.Diagnostics.AddError("missing-header-line", filePath, 0, 0,
+			"comment line and name line is required")
+		return false
+	}
+
+	// split columns
+	ok := true
+	rows := make([]*TableDataRow, 0, lineCount)
+	for i := range lineCount {
+		cols := strings.Split(lines[i], "\t")
+		if len(cols) != len(tableData.ColumnNames) {
+			this.Diagnostics.AddError("invalid-column-count", filePath, i+1, 0,
+				"column count %d is invalid, should be %d",
+				len(cols), len(tableData.ColumnNames))
+			if i < 2 {
+				return false
+			}
+			ok = false
+			continue
+		}
+		rows = append(rows,
+			NewTableDataRow(fileName, filePath, i+1, cols))
+	}
+
+	// check name line
+	nameLineOk := true
+	for i, columnName := range tableData.ColumnNames {
+		if rows[1].Columns[i] != columnName {
+			this.Diagnostics.AddError("invalid-column-name", filePath, 2, i+1,
+				"column %d should be named as `%s`", i+1, columnName)
+			nameLineOk = false
+		}
+	}
+	if nameLineOk == false {
+		return false
+	}
+
+	if tableData.CommentLine == nil {
+		tableData.CommentLine = rows[0]
+		tableData.NameLine = rows[1]
+	}
+	tableData.Rows = append(tableData.Rows, rows[2:]...)
+
+	return ok
+}
+
+func (this *TableDataReader) checkKeys(tableData *TableData) bool {
+	if tableData.TableDefRef.TableKeyType == TableKeyType_SetKey {
+		return this.checkSetKeys(tableData)
+	}
+
+	keyColumnIndex := tableData.KeyColumnIndex()
+	keyName := tableData.ColumnNames[keyColumnIndex]
+
+	// key value -> row of the key
+	checkOk := true
+	keyRows := make(map[string]*TableDataRow)
+
+	for _, row := range tableData.Rows {
+		key := UnquoteTableCell(row.Columns[keyColumnIndex])
+		if key == "" {
+			this.Diagnostics.AddError("empty-key", row.FilePath,
+				row.LineNumber, keyColumnIndex+1,
+				"key `%s` is empty", keyName)
+			checkOk = false
+			continue
+		}
+
+		key = this.normalizeKey(tableData, key)
+		if firstRow, ok := keyRows[key]; ok {
+			this.Diagnostics.AddError("duplicated-key", row.FilePath,
+				row.LineNumber, keyColumnIndex+1, ""+
+					"key `%s` value %s is duplicated, "+
+					"first defined in file `%s` line %d",
+				keyName, row.Columns[keyColumnIndex],
+				firstRow.FileName, firstRow.LineNumber)
+			checkOk = false
+		} else {
+			keyRows[key] = row
+		}
+	}
+
+	return checkOk
+}
+
+// rows of a set must be contiguous in one file,
+// empty key continues the last set in the same file
+func (this *TableDataReader) checkSetKeys(tableData *TableData) bool {
+	type keySet struct {
+		key      string
+		keyText  string
+		firstRow *TableDataRow
+		lastRow  *TableDataRow
+	}
+
+	keyColumnIndex := tableData.KeyColumnIndex()
+	keyName := tableData.ColumnNames[keyColumnIndex]
+
+	// split rows into sets
+	checkOk := true
+	sets := make([]*keySet, 0)
+	var lastSet *keySet = nil
+
+	for _, row := range tableData.Rows {
+		key := UnquoteTableCell(row.Columns[keyColumnIndex])
+		if key == "" {
+			if lastSet != nil && lastSet.lastRow.FileName == row.FileName {
+				lastSet.lastRow = row
+				continue
+			}
+			this.Diagnostics.AddError("empty-key", row.FilePath,
+				row.LineNumber, keyColumnIndex+1,
+				"key `%s` is empty", keyName)
+			checkOk = false
+			lastSet = nil
+			continue
+		}
+
+		// generated parser continues a set by the key text, so int keys
+		// of different text like 1 and 01 are different sets of one key
+		if lastSet != nil &&
+			lastSet.lastRow.FileName == row.FileName &&
+			lastSet.keyText == key {
+			lastSet.lastRow = row
+			continue
+		}
+		lastSet = &keySet{this.normalizeKey(tableData, key), key, row, row}
+		sets = append(sets, lastSet)
+	}
+
+	// key value -> first set of the key
+	keySets := make(map[string]*keySet)
+	for _, set := range sets {
+		firstSet, ok := keySets[set.key]
+		if ok == false {
+			keySets[set.key] = set
+			continue
+		}
+		this.Diagnostics.AddError("non-contiguous-set",
+			set.firstRow.FilePath, set.firstRow.LineNumber,
+			keyColumnIndex+1, ""+
+				"key `%s` value %s set in %s is not contiguous, "+
+				"first defined in file `%s` %s",
+			keyName, set.firstRow.Columns[keyColumnIndex],
+			this.getLineRangeText(set.firstRow, set.lastRow),
+			firstSet.firstRow.FileName,
+			this.getLineRangeText(firstSet.firstRow, firstSet.lastRow))
+		checkOk = false
+	}
+
+	return checkOk
+}
+
+// key is unquoted text of the cell, int key is compared by value
+func (this *TableDataReader) normalizeKey(
+	tableData *TableData, key string) string {
+
+	tableKey := tableData.TableDefRef.TableKey
+	if tableKey == nil || tableKey.Type != TableColumnType_Int {
+		return key
+	}
+	if v, err := strconv.ParseInt(key, 10, 32); err == nil {
+		return strconv.FormatInt(v, 10)
+	}
+
+	return key
+}
+
+func (this *TableDataReader) getLineRangeText(
+	firstRow *TableDataRow, lastRow *TableDataRow) string {
+
+	if firstRow == lastRow {
+		return fmt.Sprintf("line %d", firstRow.LineNumber)
+	}
+
+	return fmt.Sprintf("lines %d-%d",
+		firstRow.LineNumber, lastRow.LineNumber)
+}
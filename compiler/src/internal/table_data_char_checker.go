@@ -0,0 +1,277 @@
+package lib
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// find characters in data cells that parse differently from what they look,
+// e.g. full-width digits and separators typed by input method,
+// invisible characters and surrounding spaces,
+// in fix mode the cells are normalized in table data
+type TableDataCharChecker struct {
+	Diagnostics *DiagnosticList
+
+	fix bool
+	// source file path -> rows fixed in the file
+	fixedRows map[string][]*TableDataRow
+}
+
+func NewTableDataCharChecker(fix bool) *TableDataCharChecker {
+	newObj := new(TableDataCharChecker)
+	newObj.Diagnostics = NewDiagnosticList()
+	newObj.fix = fix
+	newObj.fixedRows = make(map[string][]*TableDataRow)
+
+	return newObj
+}
+
+func (this *TableDataCharChecker) Close() {
+	if this.fixedRows != nil {
+		clear(this.fixedRows)
+		this.fixedRows = nil
+	}
+	if this.Diagnostics != nil {
+		this.Diagnostics.Close()
+		this.Diagnostics = nil
+	}
+}
+
+// problems are errors,
+// or warnings when the cells are fixed in fix mode
+func (this *TableDataCharChecker) CheckTable(tableData *TableData) bool {
+	errorCount := this.Diagnostics.ErrorCount()
+	tableDef := tableData.TableDefRef
+
+	for _, row := range tableData.Rows {
+		rowFixed := false
+
+		for i, text := range row.Columns {
+			var columnDef *TableColumnDef
+			name := tableData.ColumnNames[i]
+			if tableDef.TableKind != TableKind_Config {
+				columnDef = tableDef.Columns[i]
+			} else if i == 2 {
+				// value column is parsed as the type of the name
+				columnDef = tableDef.ColumnNameIndex[strings.TrimSpace(
+					UnquoteTableCell(row.Columns[0]))]
+			}
+
+			fixedText, problems := this.normalizeCell(columnDef, text)
+			for _, problem := range problems {
+				if this.fix {
+					this.Diagnostics.AddWarning(problem.code,
+						row.FilePath, row.LineNumber, i+1,
+						"column `%s` %s, fixed", name, problem.message)
+				} else {
+					this.Diagnostics.AddError(problem.code,
+						row.FilePath, row.LineNumber, i+1,
+						"column `%s` %s", name, problem.message)
+				}
+			}
+			if this.fix && fixedText != text {
+				row.Columns[i] = fixedText
+				rowFixed = true
+			}
+		}
+
+		if rowFixed {
+			this.fixedRows[row.FilePath] = append(
+				this.fixedRows[row.FilePath], row)
+		}
+	}
+
+	return this.Diagnostics.ErrorCount() == errorCount
+}
+
+// write fixed rows back to their source files,
+// other lines of the files are kept as they are
+func (this *TableDataCharChecker) WriteFixedFiles() bool {
+	filePaths := make([]string, 0, len(this.fixedRows))
+	for filePath := range this.fixedRows {
+		filePaths = append(filePaths, filePath)
+	}
+	slices.Sort(filePaths)
+
+	writeOk := true
+	for _, filePath := range filePaths {
+		fileContent, err := UtilReadAllTextShared(filePath)
+		if err != nil {
+			this.Diagnostics.AddError("read-file-failed", filePath, 0, 0,
+				"read file failed: %s", err.Error())
+			writeOk = false
+			continue
+		}
+
+		lines := strings.Split(fileContent, "\r\n")
+		for _, row := range this.fixedRows[filePath] {
+			lines[row.LineNumber-1] = strings.Join(row.Columns, "\t")
+		}
+
+		if err := UtilWriteAllText(
+			filePath, strings.Join(lines, "\r\n")); err != nil {
+			this.Diagnostics.AddError("write-file-failed", filePath, 0, 0,
+				"write file failed: %s", err.Error())
+			writeOk = false
+		}
+	}
+
+	return writeOk
+}
+
+// ----------------------------------------------------------------------------
+type tableDataCharProblem struct {
+	code    string
+	message string
+}
+
+// columnDef is nil when the cell is plain text
+func (this *TableDataCharChecker) normalizeCell(
+	columnDef *TableColumnDef,
+	text string) (string, []*tableDataCharProblem) {
+
+	problems := make([]*tableDataCharProblem, 0)
+	addProblem := func(code string, format string, args ...any) {
+		problem := &tableDataCharProblem{
+			code, fmt.Sprintf(format, args...)}
+		for _, p := range problems {
+			if *p == *problem {
+				return
+			}
+		}
+		problems = append(problems, problem)
+	}
+
+	// keep the quote marks, only the text in them is normalized
+	quoted := len(text) >= 2 && text[0] == '"' && text[len(text)-1] == '"'
+	if quoted {
+		text = UnquoteTableCell(text)
+	}
+
+	// remove invisible characters
+	text = strings.Map(func(r rune) rune {
+		if this.isInvisibleChar(r) {
+			addProblem("invisible-char",
+				"contains invisible character U+%04X", r)
+			return -1
+		}
+		return r
+	}, text)
+
+	// trim surrounding spaces
+	if trimmed := strings.TrimSpace(text); trimmed != text {
+		addProblem("surrounding-space", "has leading or trailing space")
+		text = trimmed
+	}
+
+	if columnDef != nil {
+		text = this.normalizeColumnValue(columnDef, text, addProblem)
+	}
+
+	if quoted {
+		text = `"` + strings.ReplaceAll(text, `"`, `""`) + `"`
+	}
+
+	return text, problems
+}
+
+func (this *TableDataCharChecker) normalizeColumnValue(
+	columnDef *TableColumnDef, text string,
+	addProblem func(string, string, ...any)) string {
+
+	if columnDef.Type != TableColumnType_List {
+		return this.normalizeValue(columnDef.Type,
+			columnDef.RefStructDef, text, addProblem)
+	}
+	if columnDef.ListType == TableColumnType_String {
+		// full-width separator may be a part of the text
+		return text
+	}
+
+	text = this.replaceFullWidthChar(text, '｜', '|', addProblem)
+	items := strings.Split(text, "|")
+	for i, item := range items {
+		items[i] = this.normalizeValue(columnDef.ListType,
+			columnDef.RefStructDef, item, addProblem)
+	}
+
+	return strings.Join(items, "|")
+}
+
+func (this *TableDataCharChecker) normalizeValue(
+	columnType TableColumnType, structDef *StructDef, text string,
+	addProblem func(string, string, ...any)) string {
+
+	if columnType == TableColumnType_Int {
+		return this.normalizeInt(text, addProblem)
+	} else if columnType != TableColumnType_Struct {
+		return text
+	}
+
+	if len(structDef.Fields) > 1 {
+		text = this.replaceFullWidthChar(text, '；', ';', addProblem)
+	}
+	fields := strings.Split(text, ";")
+	if len(fields) != len(structDef.Fields) {
+		// field count error is reported by value parsing
+		return text
+	}
+	for i, def := range structDef.Fields {
+		if def.Type == StructFieldType_Int {
+			fields[i] = this.normalizeInt(fields[i], addProblem)
+		}
+	}
+
+	return strings.Join(fields, ";")
+}
+
+func (this *TableDataCharChecker) normalizeInt(
+	text string, addProblem func(string, string, ...any)) string {
+
+	if trimmed := strings.TrimSpace(text); trimmed != text {
+		addProblem("surrounding-space", "has leading or trailing space")
+		text = trimmed
+	}
+
+	return strings.Map(func(r rune) rune {
+		ascii := r
+		if r >= '０' && r <= '９' {
+			ascii = '0' + (r - '０')
+		} else if r == '－' {
+			ascii = '-'
+		} else if r == '＋' {
+			ascii = '+'
+		} else {
+			return r
+		}
+		addProblem("full-width-char",
+			"contains full-width character `%c`", r)
+		return ascii
+	}, text)
+}
+
+func (this *TableDataCharChecker) replaceFullWidthChar(
+	text string, fullWidth rune, ascii rune,
+	addProblem func(string, string, ...any)) string {
+
+	if strings.ContainsRune(text, fullWidth) == false {
+		return text
+	}
+	addProblem("full-width-char",
+		"contains full-width character `%c`", fullWidth)
+
+	return strings.ReplaceAll(text, string(fullWidth), string(ascii))
+}
+
+// zero width space, word joiner, byte order mark and control characters,
+// other format characters like zero width joiner are a part of the text
+func (this *TableDataCharChecker) isInvisibleChar(r rune) bool {
+	switch r {
+	case '\u200b', '\u2060', '\ufeff':
+		return true
+	}
+
+	return unicode.IsControl(r)
+}
@@ -0,0 +1,335 @@
+package lib
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+type TableDataChecker struct {
+	Diagnostics *DiagnosticList
+
+	descriptor *TableDescriptor
+	// TableDef -> key values of the table data
+	tableKeys map[*TableDef]map[string]bool
+}
+
+func NewTableDataChecker(descriptor *TableDescriptor) *TableDataChecker {
+	newObj := new(TableDataChecker)
+	newObj.Diagnostics = NewDiagnosticList()
+	newObj.descriptor = descriptor
+	newObj.tableKeys = make(map[*TableDef]map[string]bool)
+
+	return newObj
+}
+
+func (this *TableDataChecker) Close() {
+	if this.tableKeys != nil {
+		clear(this.tableKeys)
+		this.tableKeys = nil
+	}
+	if this.Diagnostics != nil {
+		this.Diagnostics.Close()
+		this.Diagnostics = nil
+	}
+	this.descriptor = nil
+}
+
+// check cell values and table refs of the tables,
+// structure and key errors are reported by TableDataReader,
+// refs to tables not in the list are not checked
+func (this *TableDataChecker) CheckTables(tableDatas []*TableData) bool {
+	errorCount := this.Diagnostics.ErrorCount()
+
+	for _, tableData := range tableDatas {
+		this.addTableKeys(tableData)
+	}
+
+	for _, tableData := range tableDatas {
+		if tableData.TableDefRef.TableKind == TableKind_Config {
+			this.checkConfigRows(tableData)
+		} else {
+			this.checkRows(tableData)
+		}
+		if tableData.TableDefRef.SetOrderColumn != nil {
+			this.checkSetOrder(tableData)
+		}
+	}
+
+	return this.Diagnostics.ErrorCount() == errorCount
+}
+
+func (this *TableDataChecker) addTableKeys(tableData *TableData) {
+	tableDef := tableData.TableDefRef
+	if tableDef.TableKind == TableKind_Config {
+		return
+	}
+
+	keys := make(map[string]bool)
+	for _, row := range tableData.Rows {
+		text := UnquoteTableCell(row.Columns[tableDef.TableKeyColumnIndex])
+		if text == "" {
+			continue
+		}
+		if tableDef.TableKey.Type == TableColumnType_Int {
+			v, err := ParseTableIntValue(text)
+			if err != nil {
+				continue
+			}
+			text = strconv.FormatInt(int64(v), 10)
+		}
+		keys[text] = true
+	}
+	this.tableKeys[tableDef] = keys
+}
+
+func (this *TableDataChecker) checkRows(tableData *TableData) {
+	tableDef := tableData.TableDefRef
+
+	for _, row := range tableData.Rows {
+		// parsed values of valid cells, used by row checks
+		values := make(map[*TableColumnDef]any)
+
+		for i, def := range tableDef.Columns {
+			text := UnquoteTableCell(row.Columns[i])
+
+			if def == tableDef.RowReadbyColumn {
+				if text != "" {
+					_, err := this.descriptor.ResolveReadby(text)
+					if err != nil {
+						this.Diagnostics.AddError("invalid-readby",
+							row.FilePath, row.LineNumber, i+1,
+							"column `%s` %s", def.Name, err.Error())
+						continue
+					}
+				}
+				values[def] = text
+				continue
+			}
+
+			value, err := ParseTableColumnValue(def, text)
+			if err != nil {
+				this.Diagnostics.AddError("invalid-value", row.FilePath,
+					row.LineNumber, i+1,
+					"column `%s` %s", def.Name, err.Error())
+				continue
+			}
+			values[def] = value
+			if err := this.checkColumnRefs(def, value); err != nil {
+				this.Diagnostics.AddError("undefined-ref", row.FilePath,
+					row.LineNumber, i+1,
+					"column `%s` %s", def.Name, err.Error())
+			}
+		}
+
+		this.checkRowExprs(tableDef, values, row.FilePath, row.LineNumber)
+	}
+}
+
+// values of set order column must be strictly increasing in each set,
+// invalid values are reported by checkRows and skipped
+func (this *TableDataChecker) checkSetOrder(tableData *TableData) {
+	tableDef := tableData.TableDefRef
+	keyColumnIndex := tableDef.TableKeyColumnIndex
+	orderColumnDef := tableDef.SetOrderColumn
+	orderColumnIndex := slices.Index(tableDef.Columns, orderColumnDef)
+
+	var lastRow *TableDataRow = nil
+	var lastValue any = nil
+	lastKey := ""
+	for _, row := range tableData.Rows {
+		// set key is checked by TableDataReader,
+		// empty key or the same key continues the set
+		key := row.Columns[keyColumnIndex]
+		if lastRow == nil || lastRow.FileName != row.FileName ||
+			(key != "" && key != lastKey) {
+			lastValue = nil
+		}
+		if key != "" {
+			lastKey = key
+		}
+		lastRow = row
+
+		text := UnquoteTableCell(row.Columns[orderColumnIndex])
+		value, err := ParseTableColumnValue(orderColumnDef, text)
+		if err != nil {
+			lastValue = nil
+			continue
+		}
+
+		if lastValue != nil {
+			ordered := false
+			if v, ok := value.(int32); ok {
+				ordered = v > lastValue.(int32)
+			} else {
+				ordered = value.(string) > lastValue.(string)
+			}
+			if ordered == false {
+				this.Diagnostics.AddError("invalid-set-order", row.FilePath,
+					row.LineNumber, orderColumnIndex+1, ""+
+						"column `%s` value %v is out of order, "+
+						"should be greater than %v of the last row in set",
+					orderColumnDef.Name, value, lastValue)
+			}
+		}
+		lastValue = value
+	}
+}
+
+func (this *TableDataChecker) checkConfigRows(tableData *TableData) {
+	tableDef := tableData.TableDefRef
+	found := make(map[*TableColumnDef]bool)
+	// parsed values of valid names, used by row checks
+	values := make(map[*TableColumnDef]any)
+
+	for _, row := range tableData.Rows {
+		name := UnquoteTableCell(row.Columns[0])
+		typeName := UnquoteTableCell(row.Columns[1])
+		text := UnquoteTableCell(row.Columns[2])
+
+		def, ok := tableDef.ColumnNameIndex[name]
+		if ok == false {
+			this.Diagnostics.AddError("undefined-config-name", row.FilePath,
+				row.LineNumber, 1,
+				"name `%s` is not defined", name)
+			continue
+		}
+		found[def] = true
+
+		if typeName != def.TypeName {
+			this.Diagnostics.AddError("invalid-config-type", row.FilePath,
+				row.LineNumber, 2,
+				"name `%s` type `%s` is invalid, should be `%s`",
+				name, typeName, def.TypeName)
+			continue
+		}
+
+		value, err := ParseTableColumnValue(def, text)
+		if err != nil {
+			this.Diagnostics.AddError("invalid-value", row.FilePath,
+				row.LineNumber, 3,
+				"name `%s` %s", name, err.Error())
+			continue
+		}
+		values[def] = value
+		if err := this.checkColumnRefs(def, value); err != nil {
+			this.Diagnostics.AddError("undefined-ref", row.FilePath,
+				row.LineNumber, 3,
+				"name `%s` %s", name, err.Error())
+		}
+	}
+
+	for _, def := range tableDef.Columns {
+		if _, ok := found[def]; ok == false {
+			this.Diagnostics.AddError("missing-config-name",
+				tableData.NameLine.FilePath, 0, 0,
+				"name `%s` is missing", def.Name)
+		}
+	}
+
+	// config table is checked as one row
+	this.checkRowExprs(tableDef, values, tableData.NameLine.FilePath, 0)
+}
+
+// checks using a column without valid value are skipped,
+// the value error is already reported
+func (this *TableDataChecker) checkRowExprs(tableDef *TableDef,
+	values map[*TableColumnDef]any, filePath string, lineNumber int) {
+
+	for _, def := range tableDef.Checks {
+		valuesOk := true
+		for _, columnDef := range def.Expr.Columns {
+			if _, ok := values[columnDef]; ok == false {
+				valuesOk = false
+				break
+			}
+		}
+		if valuesOk == false {
+			continue
+		}
+
+		ok, err := def.Expr.Evaluate(values)
+		if err != nil {
+			this.Diagnostics.AddError("check-error", filePath, lineNumber, 0,
+				"check `%s` can not be evaluated: %s",
+				def.Expr.Text, err.Error())
+		} else if ok == false {
+			if def.Message != "" {
+				this.Diagnostics.AddError("check-failed",
+					filePath, lineNumber, 0,
+					"check `%s` failed: %s", def.Expr.Text, def.Message)
+			} else {
+				this.Diagnostics.AddError("check-failed",
+					filePath, lineNumber, 0,
+					"check `%s` failed", def.Expr.Text)
+			}
+		}
+	}
+}
+
+// check the parsed value of a column refers to existing keys,
+// value 0 and empty string refer to nothing
+func (this *TableDataChecker) checkColumnRefs(
+	columnDef *TableColumnDef, value any) error {
+
+	if columnDef.Type != TableColumnType_List {
+		return this.checkItemRefs(columnDef, value)
+	}
+
+	for i, item := range value.([]any) {
+		if err := this.checkItemRefs(columnDef, item); err != nil {
+			return fmt.Errorf("list item %d %s", i+1, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (this *TableDataChecker) checkItemRefs(
+	columnDef *TableColumnDef, value any) error {
+
+	if columnDef.RefStructDef == nil {
+		return this.checkRef(columnDef.RefTableDef, value)
+	}
+
+	structDef := columnDef.RefStructDef
+	for i, fieldValue := range value.([]any) {
+		fieldDef := structDef.Fields[i]
+		if err := this.checkRef(fieldDef.RefTableDef, fieldValue); err != nil {
+			return fmt.Errorf("struct `%s` field `%s` %s",
+				structDef.Name, fieldDef.Name, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (this *TableDataChecker) checkRef(refTableDef *TableDef, value any) error {
+	if refTableDef == nil {
+		return nil
+	}
+	keys, ok := this.tableKeys[refTableDef]
+	if ok == false {
+		return nil
+	}
+
+	var key string
+	if v, ok := value.(int32); ok {
+		if v == 0 {
+			return nil
+		}
+		key = strconv.FormatInt(int64(v), 10)
+	} else {
+		key = value.(string)
+		if key == "" {
+			return nil
+		}
+	}
+
+	if _, ok := keys[key]; ok == false {
+		return fmt.Errorf("ref value %s is not found in table `%s`",
+			key, refTableDef.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// manifest of data files for hot update,
+// patcher downloads the files whose sha256 are changed,
+// and refuses the files whose schema fingerprint is different
+// from the running binary, see GetTableSchemaFingerprint
+type TableDataManifest struct {
+	// descriptor filtered by reader
+	Descriptor   *TableDescriptor
+	BuildVersion string
+	Reader       string
+	Files        []*TableDataManifestFile
+}
+
+type TableDataManifestFile struct {
+	FileName          string
+	TableName         string
+	Size              int
+	Sha256            string
+	SchemaFingerprint uint32
+}
+
+func NewTableDataManifest(descriptor *TableDescriptor,
+	reader string, buildVersion string) *TableDataManifest {
+
+	newObj := new(TableDataManifest)
+	newObj.Descriptor = descriptor
+	newObj.BuildVersion = buildVersion
+	newObj.Reader = reader
+	newObj.Files = make([]*TableDataManifestFile, 0)
+
+	return newObj
+}
+
+func (this *TableDataManifest) Close() {
+	this.Descriptor = nil
+	if this.Files != nil {
+		clear(this.Files)
+		this.Files = nil
+	}
+}
+
+func (this *TableDataManifest) AddFile(
+	tableName string, fileName string, fileContent string) {
+
+	tableDef := this.Descriptor.TableNameIndex[tableName]
+	hash := sha256.Sum256([]byte(fileContent))
+
+	this.Files = append(this.Files, &TableDataManifestFile{
+		FileName:          fileName,
+		TableName:         tableDef.Name,
+		Size:              len(fileContent),
+		Sha256:            hex.EncodeToString(hash[:]),
+		SchemaFingerprint: GetTableSchemaFingerprint(tableDef),
+	})
+}
+
+func (this *TableDataManifest) ToJson() string {
+	type jsonFile struct {
+		File              string `json:"file"`
+		Table             string `json:"table"`
+		Size              int    `json:"size"`
+		Sha256            string `json:"sha256"`
+		SchemaFingerprint uint32 `json:"schema_fingerprint"`
+	}
+	type jsonManifest struct {
+		BuildVersion string     `json:"build_version"`
+		Reader       string     `json:"reader"`
+		Files        []jsonFile `json:"files"`
+	}
+
+	output := jsonManifest{
+		BuildVersion: this.BuildVersion,
+		Reader:       this.Reader,
+		Files:        make([]jsonFile, 0, len(this.Files)),
+	}
+	for _, file := range this.Files {
+		output.Files = append(output.Files, jsonFile{
+			File:              file.FileName,
+			Table:             file.TableName,
+			Size:              file.Size,
+			Sha256:            file.Sha256,
+			SchemaFingerprint: file.SchemaFingerprint,
+		})
+	}
+
+	outputBytes, _ := json.MarshalIndent(output, "", "  ")
+
+	return string(outputBytes) + "\n"
+}
@@ -0,0 +1,295 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// patch from one release of data to the next one,
+// data is a cut data directory or a bundle file,
+// unchanged files or bundle entries are not in the patch
+type TableDataPatch struct {
+	// dir or bundle
+	Format string `json:"format"`
+	Reader string `json:"reader"`
+	// sha256 of old and new bundle file
+	OldSha256 string `json:"old_sha256,omitempty"`
+	NewSha256 string `json:"new_sha256,omitempty"`
+	// header fingerprint and entry names of new bundle file
+	Fingerprint uint32   `json:"fingerprint,omitempty"`
+	EntryNames  []string `json:"entry_names,omitempty"`
+
+	Tables []*TableDataPatchTable `json:"tables"`
+}
+
+const TableDataPatchFormat_Dir = "dir"
+const TableDataPatchFormat_Bundle = "bundle"
+
+const TableDataPatchAction_Add = "add"
+const TableDataPatchAction_Remove = "remove"
+const TableDataPatchAction_Replace = "replace"
+const TableDataPatchAction_Rows = "rows"
+
+// patch of a file in directory or an entry in bundle,
+// the whole content is shipped when rows can not be patched
+type TableDataPatchTable struct {
+	// file name relative to directory, or entry name of bundle
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	// sha256 of old and new file, or stored data of bundle entry
+	OldSha256 string `json:"old_sha256,omitempty"`
+	NewSha256 string `json:"new_sha256,omitempty"`
+	// flags of new bundle entry
+	Flags uint32 `json:"flags,omitempty"`
+	// new content of add and replace action
+	Content []byte `json:"content,omitempty"`
+
+	// row sets of rows action, keyed by table key,
+	// a set of set key table is patched as a unit
+	Removed []string                `json:"removed,omitempty"`
+	Changed []*TableDataPatchRowSet `json:"changed,omitempty"`
+	Added   []*TableDataPatchRowSet `json:"added,omitempty"`
+	// keys of new table, only when the order can not be kept
+	Order []string `json:"order,omitempty"`
+}
+
+type TableDataPatchRowSet struct {
+	Key string `json:"key"`
+	// key of the set before the added set, absent when it is the first
+	After *string `json:"after,omitempty"`
+	// cell texts of cut data file, or values of binary data
+	Rows [][]any `json:"rows"`
+}
+
+func NewTableDataPatch(format string, reader string) *TableDataPatch {
+	newObj := new(TableDataPatch)
+	newObj.Format = format
+	newObj.Reader = reader
+	newObj.Tables = make([]*TableDataPatchTable, 0)
+
+	return newObj
+}
+
+func (this *TableDataPatch) Close() {
+	if this.Tables != nil {
+		clear(this.Tables)
+		this.Tables = nil
+	}
+	this.EntryNames = nil
+}
+
+func (this *TableDataPatch) ToJson() string {
+	outputBytes, _ := json.MarshalIndent(this, "", "  ")
+
+	return string(outputBytes) + "\n"
+}
+
+func ParseTableDataPatch(text []byte) (*TableDataPatch, error) {
+	decoder := json.NewDecoder(bytes.NewReader(text))
+	decoder.UseNumber()
+
+	patch := new(TableDataPatch)
+	if err := decoder.Decode(patch); err != nil {
+		return nil, err
+	}
+	if patch.Format != TableDataPatchFormat_Dir &&
+		patch.Format != TableDataPatchFormat_Bundle {
+		return nil, fmt.Errorf("format `%s` is invalid", patch.Format)
+	}
+
+	// json numbers are int values of binary data
+	for _, table := range patch.Tables {
+		for _, rowSet := range slices.Concat(table.Changed, table.Added) {
+			for _, values := range rowSet.Rows {
+				for i, value := range values {
+					v, err := parseTableDataPatchValue(value)
+					if err != nil {
+						return nil, fmt.Errorf("table `%s` key `%s` %s",
+							table.Name, rowSet.Key, err.Error())
+					}
+					values[i] = v
+				}
+			}
+		}
+	}
+
+	return patch, nil
+}
+
+func parseTableDataPatchValue(value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		i, err := strconv.ParseInt(string(v), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("value %s is not int", v)
+		}
+		return int32(i), nil
+	case []any:
+		for i, item := range v {
+			itemValue, err := parseTableDataPatchValue(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = itemValue
+		}
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("value type is invalid")
+}
+
+// fill removed, changed and added row sets of the table,
+// return false when keys of the sets are not unique
+func diffTableDataRowSets(table *TableDataPatchTable,
+	oldRowSets []*TableDataPatchRowSet,
+	newRowSets []*TableDataPatchRowSet) bool {
+
+	oldIndex, ok := getTableDataRowSetIndex(oldRowSets)
+	if ok == false {
+		return false
+	}
+	newIndex, ok := getTableDataRowSetIndex(newRowSets)
+	if ok == false {
+		return false
+	}
+
+	for _, rowSet := range oldRowSets {
+		if _, ok := newIndex[rowSet.Key]; ok == false {
+			table.Removed = append(table.Removed, rowSet.Key)
+		}
+	}
+	for i, rowSet := range newRowSets {
+		oldRowSet, ok := oldIndex[rowSet.Key]
+		if ok == false {
+			addedRowSet := &TableDataPatchRowSet{rowSet.Key, nil, rowSet.Rows}
+			if i > 0 {
+				addedRowSet.After = &newRowSets[i-1].Key
+			}
+			table.Added = append(table.Added, addedRowSet)
+		} else if reflect.DeepEqual(oldRowSet.Rows, rowSet.Rows) == false {
+			table.Changed = append(table.Changed, rowSet)
+		}
+	}
+
+	// keep the order of new table when patching does not
+	patchedRowSets, err := applyTableDataRowSets(table, oldRowSets)
+	if err != nil || len(patchedRowSets) != len(newRowSets) {
+		return false
+	}
+	for i, rowSet := range patchedRowSets {
+		if rowSet.Key != newRowSets[i].Key {
+			table.Order = make([]string, 0, len(newRowSets))
+			for _, rowSet := range newRowSets {
+				table.Order = append(table.Order, rowSet.Key)
+			}
+			break
+		}
+	}
+
+	return true
+}
+
+// removed sets are deleted, changed sets are replaced in place,
+// added sets are inserted after the given set in order
+func applyTableDataRowSets(table *TableDataPatchTable,
+	oldRowSets []*TableDataPatchRowSet) ([]*TableDataPatchRowSet, error) {
+
+	if _, ok := getTableDataRowSetIndex(oldRowSets); ok == false {
+		return nil, fmt.Errorf("keys are not unique")
+	}
+
+	removed := make(map[string]bool)
+	for _, key := range table.Removed {
+		removed[key] = true
+	}
+	changed, ok := getTableDataRowSetIndex(table.Changed)
+	if ok == false {
+		return nil, fmt.Errorf("changed keys are not unique")
+	}
+
+	rowSets := make([]*TableDataPatchRowSet, 0, len(oldRowSets))
+	for _, rowSet := range oldRowSets {
+		if removed[rowSet.Key] {
+			delete(removed, rowSet.Key)
+			continue
+		}
+		if changedRowSet, ok := changed[rowSet.Key]; ok {
+			delete(changed, rowSet.Key)
+			rowSet = changedRowSet
+		}
+		rowSets = append(rowSets, rowSet)
+	}
+	for _, key := range table.Removed {
+		if removed[key] {
+			return nil, fmt.Errorf("removed key `%s` is not found", key)
+		}
+	}
+	for _, rowSet := range table.Changed {
+		if _, ok := changed[rowSet.Key]; ok {
+			return nil, fmt.Errorf("changed key `%s` is not found",
+				rowSet.Key)
+		}
+	}
+
+	for _, addedRowSet := range table.Added {
+		pos := 0
+		if addedRowSet.After != nil {
+			pos = slices.IndexFunc(rowSets,
+				func(rowSet *TableDataPatchRowSet) bool {
+					return rowSet.Key == *addedRowSet.After
+				})
+			if pos < 0 {
+				return nil, fmt.Errorf(""+
+					"added key `%s` after `%s` is not found",
+					addedRowSet.Key, *addedRowSet.After)
+			}
+			pos += 1
+		}
+		rowSets = slices.Insert(rowSets, pos,
+			&TableDataPatchRowSet{addedRowSet.Key, nil, addedRowSet.Rows})
+	}
+	index, ok := getTableDataRowSetIndex(rowSets)
+	if ok == false {
+		return nil, fmt.Errorf("added keys are not unique")
+	}
+
+	if len(table.Order) == 0 {
+		return rowSets, nil
+	}
+	if len(table.Order) != len(rowSets) {
+		return nil, fmt.Errorf(""+
+			"order key count %d is invalid, should be %d",
+			len(table.Order), len(rowSets))
+	}
+	orderedRowSets := make([]*TableDataPatchRowSet, 0, len(rowSets))
+	for _, key := range table.Order {
+		rowSet, ok := index[key]
+		if ok == false {
+			return nil, fmt.Errorf("order key `%s` is not found", key)
+		}
+		delete(index, key)
+		orderedRowSets = append(orderedRowSets, rowSet)
+	}
+
+	return orderedRowSets, nil
+}
+
+func getTableDataRowSetIndex(
+	rowSets []*TableDataPatchRowSet) (map[string]*TableDataPatchRowSet, bool) {
+
+	index := make(map[string]*TableDataPatchRowSet, len(rowSets))
+	for _, rowSet := range rowSets {
+		if _, ok := index[rowSet.Key]; ok {
+			return nil, false
+		}
+		index[rowSet.Key] = rowSet
+	}
+
+	return index, true
+}
@@ -0,0 +1,669 @@
+package lib
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// make and apply patch between two releases of data,
+// rows of a table are patched by table key,
+// when the table layout is changed or rows can not be patched exactly,
+// the whole file or bundle entry is shipped
+type TableDataPatcher struct {
+	Diagnostics *DiagnosticList
+
+	// descriptor filtered by reader
+	descriptor     *TableDescriptor
+	reader         string
+	binaryExporter *BinaryDataExporter
+	// file name -> table define
+	fileNameIndex map[string]*TableDef
+}
+
+func NewTableDataPatcher(
+	descriptor *TableDescriptor, reader string) *TableDataPatcher {
+
+	newObj := new(TableDataPatcher)
+	newObj.Diagnostics = NewDiagnosticList()
+	newObj.descriptor = descriptor
+	newObj.reader = reader
+	newObj.binaryExporter = NewBinaryDataExporter()
+	newObj.fileNameIndex = make(map[string]*TableDef)
+
+	for _, def := range descriptor.Tables {
+		newObj.fileNameIndex[def.FileName] = def
+	}
+
+	return newObj
+}
+
+func (this *TableDataPatcher) Close() {
+	if this.fileNameIndex != nil {
+		clear(this.fileNameIndex)
+		this.fileNameIndex = nil
+	}
+	if this.binaryExporter != nil {
+		this.binaryExporter.Close()
+		this.binaryExporter = nil
+	}
+	if this.Diagnostics != nil {
+		this.Diagnostics.Close()
+		this.Diagnostics = nil
+	}
+	this.descriptor = nil
+}
+
+// make patch from old cut data directory to new one,
+// return nil when failed
+func (this *TableDataPatcher) DiffDirs(
+	oldDir string, newDir string) *TableDataPatch {
+
+	oldFiles, ok := this.readDir(oldDir)
+	if ok == false {
+		return nil
+	}
+	newFiles, ok := this.readDir(newDir)
+	if ok == false {
+		return nil
+	}
+
+	fileNames := make([]string, 0, len(oldFiles)+len(newFiles))
+	for fileName := range oldFiles {
+		fileNames = append(fileNames, fileName)
+	}
+	for fileName := range newFiles {
+		if _, ok := oldFiles[fileName]; ok == false {
+			fileNames = append(fileNames, fileName)
+		}
+	}
+	slices.Sort(fileNames)
+
+	patch := NewTableDataPatch(TableDataPatchFormat_Dir, this.reader)
+	for _, fileName := range fileNames {
+		oldContent, oldOk := oldFiles[fileName]
+		newContent, newOk := newFiles[fileName]
+		if oldOk && newOk && bytes.Equal(oldContent, newContent) {
+			continue
+		}
+
+		table := &TableDataPatchTable{Name: fileName}
+		if oldOk {
+			table.OldSha256 = this.getSha256Text(oldContent)
+		}
+		if newOk {
+			table.NewSha256 = this.getSha256Text(newContent)
+		}
+		if oldOk == false {
+			table.Action = TableDataPatchAction_Add
+			table.Content = newContent
+		} else if newOk == false {
+			table.Action = TableDataPatchAction_Remove
+		} else if this.diffFileRows(table, oldContent, newContent) {
+			table.Action = TableDataPatchAction_Rows
+		} else {
+			table.Action = TableDataPatchAction_Replace
+			table.Content = newContent
+		}
+		patch.Tables = append(patch.Tables, table)
+	}
+
+	return patch
+}
+
+// make patch from old bundle file to new one,
+// return nil when failed
+func (this *TableDataPatcher) DiffBundles(
+	oldFilePath string, newFilePath string) *TableDataPatch {
+
+	oldData, oldBundle := this.readBundle(oldFilePath)
+	if oldBundle == nil {
+		return nil
+	}
+	defer oldBundle.Close()
+	newData, newBundle := this.readBundle(newFilePath)
+	if newBundle == nil {
+		return nil
+	}
+	defer newBundle.Close()
+
+	patch := NewTableDataPatch(TableDataPatchFormat_Bundle, this.reader)
+	patch.OldSha256 = this.getSha256Text(oldData)
+	patch.NewSha256 = this.getSha256Text(newData)
+	patch.Fingerprint = newBundle.Fingerprint
+	patch.EntryNames = make([]string, 0, len(newBundle.Entries))
+
+	// entry name -> entry of old bundle
+	oldEntries := make(map[string]*TableBundleEntry)
+	for _, entry := range oldBundle.Entries {
+		oldEntries[entry.Name] = entry
+	}
+
+	for _, entry := range newBundle.Entries {
+		patch.EntryNames = append(patch.EntryNames, entry.Name)
+
+		oldEntry, ok := oldEntries[entry.Name]
+		delete(oldEntries, entry.Name)
+		if ok &&
+			oldEntry.Flags == entry.Flags &&
+			bytes.Equal(oldEntry.Data, entry.Data) {
+			continue
+		}
+
+		table := &TableDataPatchTable{Name: entry.Name}
+		if ok {
+			table.OldSha256 = this.getSha256Text(oldEntry.Data)
+		}
+		table.NewSha256 = this.getSha256Text(entry.Data)
+		table.Flags = entry.Flags
+		if ok == false {
+			table.Action = TableDataPatchAction_Add
+			table.Content = entry.Data
+		} else if this.diffEntryRows(table, oldEntry, entry) {
+			table.Action = TableDataPatchAction_Rows
+		} else {
+			table.Action = TableDataPatchAction_Replace
+			table.Content = entry.Data
+		}
+		patch.Tables = append(patch.Tables, table)
+	}
+
+	// removed entries
+	for _, entry := range oldBundle.Entries {
+		if _, ok := oldEntries[entry.Name]; ok == false {
+			continue
+		}
+		patch.Tables = append(patch.Tables, &TableDataPatchTable{
+			Name:      entry.Name,
+			Action:    TableDataPatchAction_Remove,
+			OldSha256: this.getSha256Text(entry.Data),
+		})
+	}
+
+	return patch
+}
+
+// write new cut data directory from old one and the patch,
+// files of removed tables are not written, so outputDir must not be oldDir
+func (this *TableDataPatcher) ApplyToDir(patch *TableDataPatch,
+	oldDir string, outputDir string) bool {
+
+	if this.checkPatch(patch, TableDataPatchFormat_Dir) == false {
+		return false
+	}
+	oldFiles, ok := this.readDir(oldDir)
+	if ok == false {
+		return false
+	}
+
+	// patch files
+	newFiles := make(map[string][]byte, len(oldFiles))
+	for fileName, content := range oldFiles {
+		newFiles[fileName] = content
+	}
+	for _, table := range patch.Tables {
+		tableDef, ok := this.fileNameIndex[table.Name]
+		if ok == false {
+			this.addPatchError(table, "table is not defined")
+			return false
+		}
+		oldContent, ok := oldFiles[table.Name]
+		if ok == false && table.Action != TableDataPatchAction_Add {
+			this.addPatchError(table, "file is not found")
+			return false
+		}
+		if ok && this.getSha256Text(oldContent) != table.OldSha256 {
+			this.addPatchError(table, "file sha256 mismatch")
+			return false
+		}
+
+		var newContent []byte = nil
+		if table.Action == TableDataPatchAction_Add ||
+			table.Action == TableDataPatchAction_Replace {
+			newContent = table.Content
+		} else if table.Action == TableDataPatchAction_Rows {
+			content, err := this.applyFileRows(tableDef, table, oldContent)
+			if err != nil {
+				this.addPatchError(table, err.Error())
+				return false
+			}
+			newContent = content
+		} else if table.Action == TableDataPatchAction_Remove {
+			delete(newFiles, table.Name)
+			continue
+		} else {
+			this.addPatchError(table, fmt.Sprintf(
+				"action `%s` is invalid", table.Action))
+			return false
+		}
+
+		if this.getSha256Text(newContent) != table.NewSha256 {
+			this.addPatchError(table, "patched file sha256 mismatch")
+			return false
+		}
+		newFiles[table.Name] = newContent
+	}
+
+	// write files
+	fileNames := make([]string, 0, len(newFiles))
+	for fileName := range newFiles {
+		fileNames = append(fileNames, fileName)
+	}
+	slices.Sort(fileNames)
+	for _, fileName := range fileNames {
+		filePath := filepath.Join(outputDir, filepath.FromSlash(fileName))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			this.Diagnostics.AddError("write-file-failed", filePath, 0, 0,
+				"write file failed: %s", err.Error())
+			return false
+		}
+		if err := UtilWriteAllText(
+			filePath, string(newFiles[fileName])); err != nil {
+			this.Diagnostics.AddError("write-file-failed", filePath, 0, 0,
+				"write file failed: %s", err.Error())
+			return false
+		}
+	}
+
+	return true
+}
+
+// write new bundle file from old one and the patch
+func (this *TableDataPatcher) ApplyToBundle(patch *TableDataPatch,
+	oldFilePath string, outputFilePath string) bool {
+
+	if this.checkPatch(patch, TableDataPatchFormat_Bundle) == false {
+		return false
+	}
+	oldData, oldBundle := this.readBundle(oldFilePath)
+	if oldBundle == nil {
+		return false
+	}
+	defer oldBundle.Close()
+	if this.getSha256Text(oldData) != patch.OldSha256 {
+		this.Diagnostics.AddError("patch-mismatch", oldFilePath, 0, 0,
+			"bundle sha256 mismatch")
+		return false
+	}
+
+	// entry name -> entry of old bundle
+	oldEntries := make(map[string]*TableBundleEntry)
+	for _, entry := range oldBundle.Entries {
+		oldEntries[entry.Name] = entry
+	}
+	// entry name -> patch of the entry
+	tables := make(map[string]*TableDataPatchTable)
+	for _, table := range patch.Tables {
+		tables[table.Name] = table
+	}
+
+	// patch entries
+	newBundle := NewTableBundle(patch.Fingerprint)
+	defer newBundle.Close()
+	for _, name := range patch.EntryNames {
+		oldEntry := oldEntries[name]
+		table, ok := tables[name]
+		if ok == false {
+			if oldEntry == nil {
+				this.Diagnostics.AddError("patch-mismatch",
+					oldFilePath, 0, 0,
+					"entry `%s` is not found", name)
+				return false
+			}
+			newBundle.Entries = append(newBundle.Entries, oldEntry)
+			continue
+		}
+
+		if oldEntry == nil && table.Action != TableDataPatchAction_Add {
+			this.addPatchError(table, "entry is not found")
+			return false
+		}
+		if oldEntry != nil &&
+			this.getSha256Text(oldEntry.Data) != table.OldSha256 {
+			this.addPatchError(table, "entry sha256 mismatch")
+			return false
+		}
+
+		var newEntry *TableBundleEntry = nil
+		if table.Action == TableDataPatchAction_Add ||
+			table.Action == TableDataPatchAction_Replace {
+			newEntry = &TableBundleEntry{
+				name, table.Flags, len(table.Content), table.Content}
+			data, err := newEntry.RawData()
+			if err != nil {
+				this.addPatchError(table, err.Error())
+				return false
+			}
+			newEntry.RawSize = len(data)
+		} else if table.Action == TableDataPatchAction_Rows {
+			entry, err := this.applyEntryRows(table, oldEntry)
+			if err != nil {
+				this.addPatchError(table, err.Error())
+				return false
+			}
+			newEntry = entry
+		} else {
+			this.addPatchError(table, fmt.Sprintf(
+				"action `%s` is invalid", table.Action))
+			return false
+		}
+
+		if this.getSha256Text(newEntry.Data) != table.NewSha256 {
+			this.addPatchError(table, "patched entry sha256 mismatch")
+			return false
+		}
+		newBundle.Entries = append(newBundle.Entries, newEntry)
+	}
+
+	newData := newBundle.Encode()
+	if this.getSha256Text(newData) != patch.NewSha256 {
+		this.Diagnostics.AddError("patch-mismatch", outputFilePath, 0, 0,
+			"patched bundle sha256 mismatch")
+		return false
+	}
+	if err := UtilWriteAllText(outputFilePath, string(newData)); err != nil {
+		this.Diagnostics.AddError("write-file-failed",
+			outputFilePath, 0, 0,
+			"write file failed: %s", err.Error())
+		return false
+	}
+
+	return true
+}
+
+func (this *TableDataPatcher) checkPatch(
+	patch *TableDataPatch, format string) bool {
+
+	if patch.Format != format {
+		this.Diagnostics.AddError("patch-mismatch", "", 0, 0,
+			"patch format `%s` is invalid, should be `%s`",
+			patch.Format, format)
+		return false
+	}
+	if patch.Reader != this.reader {
+		this.Diagnostics.AddError("patch-mismatch", "", 0, 0,
+			"patch reader `%s` is invalid, should be `%s`",
+			patch.Reader, this.reader)
+		return false
+	}
+
+	return true
+}
+
+func (this *TableDataPatcher) addPatchError(
+	table *TableDataPatchTable, message string) {
+
+	this.Diagnostics.AddError("patch-mismatch", "", 0, 0,
+		"patch `%s` %s failed: %s", table.Name, table.Action, message)
+}
+
+// files of the directory and sub directories,
+// file name is relative to the directory
+// only data files of the tables are read,
+// other files like manifest.json are not patched
+func (this *TableDataPatcher) readDir(dir string) (map[string][]byte, bool) {
+	files := make(map[string][]byte)
+
+	for _, def := range this.descriptor.Tables {
+		filePath := filepath.Join(dir, def.FileName)
+		if UtilCheckFileExists(filePath) == false {
+			continue
+		}
+		content, err := UtilReadAllBytesShared(filePath)
+		if err != nil {
+			this.Diagnostics.AddError("read-file-failed", filePath, 0, 0,
+				"read file failed: %s", err.Error())
+			return nil, false
+		}
+		files[def.FileName] = content
+	}
+
+	return files, true
+}
+
+func (this *TableDataPatcher) readBundle(
+	filePath string) ([]byte, *TableBundle) {
+
+	data, err := UtilReadAllBytesShared(filePath)
+	if err != nil {
+		this.Diagnostics.AddError("read-file-failed", filePath, 0, 0,
+			"read file failed: %s", err.Error())
+		return nil, nil
+	}
+	bundle, err := DecodeTableBundle(data)
+	if err != nil {
+		this.Diagnostics.AddError("invalid-bundle", filePath, 0, 0,
+			"%s", err.Error())
+		return nil, nil
+	}
+
+	return data, bundle
+}
+
+// rows are patched only when patched content is the same as new one
+func (this *TableDataPatcher) diffFileRows(table *TableDataPatchTable,
+	oldContent []byte, newContent []byte) bool {
+
+	tableDef, ok := this.fileNameIndex[table.Name]
+	if ok == false {
+		return false
+	}
+	oldHeader, oldRowSets, ok := this.splitFileRows(tableDef, oldContent)
+	if ok == false {
+		return false
+	}
+	newHeader, newRowSets, ok := this.splitFileRows(tableDef, newContent)
+	if ok == false || oldHeader != newHeader {
+		return false
+	}
+	if diffTableDataRowSets(table, oldRowSets, newRowSets) == false {
+		this.clearTableRows(table)
+		return false
+	}
+
+	content, err := this.applyFileRows(tableDef, table, oldContent)
+	if err != nil || bytes.Equal(content, newContent) == false {
+		this.clearTableRows(table)
+		return false
+	}
+
+	return true
+}
+
+func (this *TableDataPatcher) applyFileRows(tableDef *TableDef,
+	table *TableDataPatchTable, oldContent []byte) ([]byte, error) {
+
+	header, oldRowSets, ok := this.splitFileRows(tableDef, oldContent)
+	if ok == false {
+		return nil, fmt.Errorf("file layout is invalid")
+	}
+	rowSets, err := applyTableDataRowSets(table, oldRowSets)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(header)
+	for _, rowSet := range rowSets {
+		for _, values := range rowSet.Rows {
+			for i, value := range values {
+				text, ok := value.(string)
+				if ok == false {
+					return nil, fmt.Errorf("key `%s` cell is not string",
+						rowSet.Key)
+				}
+				if i > 0 {
+					sb.WriteString("\t")
+				}
+				sb.WriteString(text)
+			}
+			sb.WriteString("\r\n")
+		}
+	}
+
+	return []byte(sb.String()), nil
+}
+
+// split cut data file to header lines and row sets,
+// return false when the file is not in the layout of the table define
+func (this *TableDataPatcher) splitFileRows(tableDef *TableDef,
+	content []byte) (string, []*TableDataPatchRowSet, bool) {
+
+	lines := strings.Split(string(content), "\r\n")
+	if len(lines) < 3 || lines[len(lines)-1] != "" {
+		return "", nil, false
+	}
+	lines = lines[:len(lines)-1]
+
+	tableData := NewTableData(tableDef)
+	defer tableData.Close()
+	if lines[1] != strings.Join(tableData.ColumnNames, "\t") {
+		return "", nil, false
+	}
+	keyColumnIndex := tableData.KeyColumnIndex()
+	isSetKey := tableDef.TableKeyType == TableKeyType_SetKey
+
+	rowSets := make([]*TableDataPatchRowSet, 0, len(lines)-2)
+	var lastRowSet *TableDataPatchRowSet = nil
+	for _, line := range lines[2:] {
+		cols := strings.Split(line, "\t")
+		if len(cols) != len(tableData.ColumnNames) {
+			return "", nil, false
+		}
+		values := make([]any, 0, len(cols))
+		for _, col := range cols {
+			values = append(values, col)
+		}
+
+		key := cols[keyColumnIndex]
+		if isSetKey && lastRowSet != nil &&
+			(key == "" || key == lastRowSet.Key) {
+			lastRowSet.Rows = append(lastRowSet.Rows, values)
+			continue
+		}
+		if key == "" {
+			return "", nil, false
+		}
+		lastRowSet = &TableDataPatchRowSet{key, nil, [][]any{values}}
+		rowSets = append(rowSets, lastRowSet)
+	}
+
+	return lines[0] + "\r\n" + lines[1] + "\r\n", rowSets, true
+}
+
+// rows are patched only when patched entry is the same as new one
+func (this *TableDataPatcher) diffEntryRows(table *TableDataPatchTable,
+	oldEntry *TableBundleEntry, newEntry *TableBundleEntry) bool {
+
+	tableDef, ok := this.descriptor.TableNameIndex[table.Name]
+	if ok == false {
+		return false
+	}
+	oldRowSets, err := this.splitEntryRows(tableDef, oldEntry)
+	if err != nil {
+		return false
+	}
+	newRowSets, err := this.splitEntryRows(tableDef, newEntry)
+	if err != nil {
+		return false
+	}
+	if diffTableDataRowSets(table, oldRowSets, newRowSets) == false {
+		this.clearTableRows(table)
+		return false
+	}
+
+	entry, err := this.applyEntryRows(table, oldEntry)
+	if err != nil ||
+		entry.RawSize != newEntry.RawSize ||
+		bytes.Equal(entry.Data, newEntry.Data) == false {
+		this.clearTableRows(table)
+		return false
+	}
+
+	return true
+}
+
+func (this *TableDataPatcher) applyEntryRows(table *TableDataPatchTable,
+	oldEntry *TableBundleEntry) (*TableBundleEntry, error) {
+
+	tableDef, ok := this.descriptor.TableNameIndex[table.Name]
+	if ok == false {
+		return nil, fmt.Errorf("table is not defined")
+	}
+	oldRowSets, err := this.splitEntryRows(tableDef, oldEntry)
+	if err != nil {
+		return nil, err
+	}
+	rowSets, err := applyTableDataRowSets(table, oldRowSets)
+	if err != nil {
+		return nil, err
+	}
+
+	rowValues := make([][]any, 0, len(rowSets))
+	for _, rowSet := range rowSets {
+		rowValues = append(rowValues, rowSet.Rows...)
+	}
+	data := []byte(this.binaryExporter.encodeTable(tableDef, rowValues))
+
+	return NewTableBundleEntry(table.Name, data,
+		table.Flags&BundleEntryFlag_Deflate != 0)
+}
+
+// split binary data of bundle entry to row sets,
+// config table is one row set with empty key
+func (this *TableDataPatcher) splitEntryRows(tableDef *TableDef,
+	entry *TableBundleEntry) ([]*TableDataPatchRowSet, error) {
+
+	data, err := entry.RawData()
+	if err != nil {
+		return nil, err
+	}
+	rowValues, err := DecodeTableBinary(tableDef, data)
+	if err != nil {
+		return nil, err
+	}
+
+	rowSets := make([]*TableDataPatchRowSet, 0, len(rowValues))
+	if tableDef.TableKind == TableKind_Config {
+		return append(rowSets,
+			&TableDataPatchRowSet{"", nil, rowValues}), nil
+	}
+
+	isSetKey := tableDef.TableKeyType == TableKeyType_SetKey
+	var lastRowSet *TableDataPatchRowSet = nil
+	for _, values := range rowValues {
+		var key string
+		switch v := values[tableDef.TableKeyColumnIndex].(type) {
+		case int32:
+			key = strconv.FormatInt(int64(v), 10)
+		case string:
+			key = v
+		}
+		if isSetKey && lastRowSet != nil && key == lastRowSet.Key {
+			lastRowSet.Rows = append(lastRowSet.Rows, values)
+			continue
+		}
+		lastRowSet = &TableDataPatchRowSet{key, nil, [][]any{values}}
+		rowSets = append(rowSets, lastRowSet)
+	}
+
+	return rowSets, nil
+}
+
+func (this *TableDataPatcher) clearTableRows(table *TableDataPatchTable) {
+	table.Removed = nil
+	table.Changed = nil
+	table.Added = nil
+	table.Order = nil
+}
+
+func (this *TableDataPatcher) getSha256Text(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,114 @@
+package lib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// values parsed from data file cells:
+//   int    -> int32
+//   string -> string
+//   struct -> []any, in struct field define order
+//   list   -> []any
+
+// remove the quote marks the same way as generated parser does
+func UnquoteTableCell(text string) string {
+	if len(text) >= 2 &&
+		text[0] == '"' && text[len(text)-1] == '"' {
+		return strings.ReplaceAll(text[1:len(text)-1], `""`, `"`)
+	}
+
+	return text
+}
+
+func ParseTableIntValue(text string) (int32, error) {
+	if text == "" {
+		return 0, nil
+	}
+
+	v, err := strconv.ParseInt(text, 10, 32)
+	if err != nil {
+		if numErr, ok := err.(*strconv.NumError); ok &&
+			numErr.Err == strconv.ErrRange {
+			return 0, fmt.Errorf("int value `%s` is out of range", text)
+		}
+		return 0, fmt.Errorf("int value `%s` is invalid", text)
+	}
+
+	return int32(v), nil
+}
+
+func ParseTableStructValue(
+	structDef *StructDef, text string) ([]any, error) {
+
+	if len(structDef.Fields) == 0 {
+		return []any{}, nil
+	}
+
+	parts := strings.Split(text, ";")
+	if len(parts) != len(structDef.Fields) {
+		return nil, fmt.Errorf(
+			"struct `%s` field count %d is invalid, should be %d",
+			structDef.Name, len(parts), len(structDef.Fields))
+	}
+
+	values := make([]any, 0, len(parts))
+	for i, def := range structDef.Fields {
+		if def.Type == StructFieldType_Int {
+			v, err := ParseTableIntValue(parts[i])
+			if err != nil {
+				return nil, fmt.Errorf(
+					"struct `%s` field `%s` %s",
+					structDef.Name, def.Name, err.Error())
+			}
+			values = append(values, v)
+		} else {
+			values = append(values, parts[i])
+		}
+	}
+
+	return values, nil
+}
+
+// parse the unquoted text of a column cell
+func ParseTableColumnValue(
+	columnDef *TableColumnDef, text string) (any, error) {
+
+	if columnDef.Type == TableColumnType_Int {
+		return ParseTableIntValue(text)
+	} else if columnDef.Type == TableColumnType_String {
+		return text, nil
+	} else if columnDef.Type == TableColumnType_Struct {
+		return ParseTableStructValue(columnDef.RefStructDef, text)
+	}
+
+	values := make([]any, 0)
+	if text == "" {
+		return values, nil
+	}
+
+	for i, item := range strings.Split(text, "|") {
+		if columnDef.ListType == TableColumnType_String {
+			values = append(values, item)
+			continue
+		}
+		if item == "" {
+			return nil, fmt.Errorf("list item %d is empty", i+1)
+		}
+
+		var v any
+		var err error
+		if columnDef.ListType == TableColumnType_Int {
+			v, err = ParseTableIntValue(item)
+		} else {
+			v, err = ParseTableStructValue(columnDef.RefStructDef, item)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("list item %d %s", i+1, err.Error())
+		}
+		values = append(values, v)
+	}
+
+	return values, nil
+}
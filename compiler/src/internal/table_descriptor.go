@@ -1,5 +1,11 @@
 package lib
 
+import (
+	"fmt"
+	"maps"
+	"strings"
+)
+
 type TableDescriptor struct {
 	FilePath string
 
@@ -7,6 +13,10 @@ type TableDescriptor struct {
 	// ReaderDef.Name -> ReaderDef
 	Readers map[string]*ReaderDef
 
+	// reader group define
+	// ReaderGroupDef.Name -> ReaderGroupDef
+	ReaderGroups map[string]*ReaderGroupDef
+
 	// global struct define
 	// in file define order
 	GlobalStructs []*StructDef
@@ -24,6 +34,7 @@ func NewTableDescriptor(filePath string) *TableDescriptor {
 	newObj := new(TableDescriptor)
 	newObj.FilePath = filePath
 	newObj.Readers = make(map[string]*ReaderDef)
+	newObj.ReaderGroups = make(map[string]*ReaderGroupDef)
 	newObj.GlobalStructs = make([]*StructDef, 0)
 	newObj.GlobalStructNameIndex = make(map[string]*StructDef)
 	newObj.Tables = make([]*TableDef, 0)
@@ -55,6 +66,13 @@ func (this *TableDescriptor) Close() {
 		clear(this.GlobalStructs)
 		this.GlobalStructs = nil
 	}
+	if this.ReaderGroups != nil {
+		for _, def := range this.ReaderGroups {
+			def.Close()
+		}
+		clear(this.ReaderGroups)
+		this.ReaderGroups = nil
+	}
 	if this.Readers != nil {
 		for _, def := range this.Readers {
 			def.Close()
@@ -64,6 +82,74 @@ func (this *TableDescriptor) Close() {
 	}
 }
 
+// resolve readby string to reader set,
+// `name` is a reader and `@name` is a reader group,
+// readers extending a listed reader are included too,
+// `!` prefixed terms are excluded from the set,
+// when only excluded terms are given, the set starts with all readers
+func (this *TableDescriptor) ResolveReadby(
+	readby string) (map[string]*ReaderDef, error) {
+
+	includedReaders := make(map[string]*ReaderDef)
+	excludedReaders := make(map[string]*ReaderDef)
+	hasIncludedTerm := false
+
+	for term := range strings.SplitSeq(readby, "|") {
+		targetReaders := includedReaders
+		if name, ok := strings.CutPrefix(term, "!"); ok {
+			term = name
+			targetReaders = excludedReaders
+		} else {
+			hasIncludedTerm = true
+		}
+
+		if groupName, ok := strings.CutPrefix(term, "@"); ok {
+			groupDef, ok := this.ReaderGroups[groupName]
+			if ok == false {
+				return nil, fmt.Errorf(
+					"reader group `%s` is not defined", groupName)
+			}
+			maps.Copy(targetReaders, groupDef.Readers)
+		} else {
+			readerDef, ok := this.Readers[term]
+			if ok == false {
+				return nil, fmt.Errorf(
+					"reader `%s` is not defined", term)
+			}
+			targetReaders[readerDef.Name] = readerDef
+		}
+	}
+
+	if hasIncludedTerm == false {
+		maps.Copy(includedReaders, this.Readers)
+	}
+	this.addExtendedReaders(includedReaders)
+	this.addExtendedReaders(excludedReaders)
+	for name := range excludedReaders {
+		delete(includedReaders, name)
+	}
+
+	if len(includedReaders) == 0 {
+		return nil, fmt.Errorf(
+			"readby `%s` excludes every reader", readby)
+	}
+
+	return includedReaders, nil
+}
+
+func (this *TableDescriptor) addExtendedReaders(readers map[string]*ReaderDef) {
+	for _, readerDef := range this.Readers {
+		baseDef := readerDef.BaseReaderRef
+		for baseDef != nil {
+			if _, ok := readers[baseDef.Name]; ok {
+				readers[readerDef.Name] = readerDef
+				break
+			}
+			baseDef = baseDef.BaseReaderRef
+		}
+	}
+}
+
 // ----------------------------------------------------------------------------
 type ReaderDef struct {
 	// reader name
@@ -71,6 +157,9 @@ type ReaderDef struct {
 	// define in line number
 	LineNumber int
 
+	// link to extended reader define, null when reader extends nothing
+	BaseReaderRef *ReaderDef
+
 	Namespace      string
 	NamespaceParts []string
 }
@@ -85,6 +174,35 @@ func NewReadDef(name string, lineNumber int) *ReaderDef {
 
 func (this *ReaderDef) Close() {
 	this.NamespaceParts = nil
+	this.BaseReaderRef = nil
+}
+
+// ----------------------------------------------------------------------------
+type ReaderGroupDef struct {
+	// reader group name
+	Name string
+	// define in line number
+	LineNumber int
+
+	// readers attribute as written in define file
+	ReadersText string
+	Readers     map[string]*ReaderDef
+}
+
+func NewReaderGroupDef(name string, lineNumber int) *ReaderGroupDef {
+	newObj := new(ReaderGroupDef)
+	newObj.Name = name
+	newObj.LineNumber = lineNumber
+	newObj.Readers = make(map[string]*ReaderDef)
+
+	return newObj
+}
+
+func (this *ReaderGroupDef) Close() {
+	if this.Readers != nil {
+		clear(this.Readers)
+		this.Readers = nil
+	}
 }
 
 // ----------------------------------------------------------------------------
@@ -104,8 +222,14 @@ type StructFieldDef struct {
 	Name string
 	// define in line number
 	LineNumber int
+	// description
+	Description string
 
 	Type StructFieldType
+	// referenced table name, empty when field is not a reference
+	RefTableName string
+	// link to referenced table define
+	RefTableDef *TableDef
 }
 
 func NewStructFieldDef(
@@ -120,6 +244,7 @@ func NewStructFieldDef(
 }
 
 func (this *StructFieldDef) Close() {
+	this.RefTableDef = nil
 	this.ParentRef = nil
 }
 
@@ -131,6 +256,8 @@ type StructDef struct {
 	Name string
 	// define in line number
 	LineNumber int
+	// description
+	Description string
 
 	// in file define order
 	Fields []*StructFieldDef
@@ -186,6 +313,18 @@ const (
 	TableKeyType_SetKey
 )
 
+// ----------------------------------------------------------------------------
+type TableKind int
+
+const (
+	TableKind_None TableKind = iota
+	TableKind_Normal
+	TableKind_Config
+)
+
+// column names of config table data file
+var ConfigTableColumnNames = []string{"name", "type", "value"}
+
 // ----------------------------------------------------------------------------
 type TableColumnDef struct {
 	// link to parent define
@@ -194,11 +333,20 @@ type TableColumnDef struct {
 	Name string
 	// define in line number
 	LineNumber int
+	// description
+	Description string
 
 	Type         TableColumnType
+	TypeName     string
 	ListType     TableColumnType
 	RefStructDef *StructDef
-	Readers      map[string]*ReaderDef
+	// readby attribute as written in define file, empty when not set
+	ReadbyText string
+	Readers    map[string]*ReaderDef
+	// referenced table name, empty when column is not a reference
+	RefTableName string
+	// link to referenced table define
+	RefTableDef *TableDef
 }
 
 func NewTableColumnDef(
@@ -218,27 +366,67 @@ func (this *TableColumnDef) Close() {
 		clear(this.Readers)
 		this.Readers = nil
 	}
+	this.RefTableDef = nil
 	this.RefStructDef = nil
 	this.ParentRef = nil
 }
 
+// ----------------------------------------------------------------------------
+type TableCheckDef struct {
+	// link to parent define
+	ParentRef *TableDef
+	// define in line number
+	LineNumber int
+	// message shown when check fails, empty when not set
+	Message string
+
+	Expr *TableCheckExpr
+}
+
+func NewTableCheckDef(
+	parentRef *TableDef, lineNumber int) *TableCheckDef {
+
+	newObj := new(TableCheckDef)
+	newObj.ParentRef = parentRef
+	newObj.LineNumber = lineNumber
+
+	return newObj
+}
+
+func (this *TableCheckDef) Close() {
+	this.Expr = nil
+	this.ParentRef = nil
+}
+
 // ----------------------------------------------------------------------------
 type TableDef struct {
 	// table name
 	Name string
 	// define in line number
 	LineNumber int
+	// description
+	Description string
 
-	// table key
+	// table kind
+	TableKind TableKind
+	// table key, null when table is config table
 	TableKey *TableColumnDef
 	// table key type
 	TableKeyType TableKeyType
 	// table key column index
 	TableKeyColumnIndex int
-	// file name
+	// data file name, also the file name of cutter output
 	FileName string
+	// source data file names or glob patterns, in file define order
+	SourceFileNames []string
+	// readby attribute as written in define file, empty when not set
+	ReadbyText string
 	// read by
 	Readers map[string]*ReaderDef
+	// column listing readers of each row, null when rows are not filtered
+	RowReadbyColumn *TableColumnDef
+	// column rows of a set are ordered by, null when not set
+	SetOrderColumn *TableColumnDef
 	// in file define order
 	LocalStructs []*StructDef
 	// StructDef.Name -> StructDef
@@ -247,22 +435,33 @@ type TableDef struct {
 	Columns []*TableColumnDef
 	// ColumnDef.Name -> ColumnDef
 	ColumnNameIndex map[string]*TableColumnDef
+	// row checks, in file define order
+	Checks []*TableCheckDef
 }
 
 func NewTableDef(name string, lineNumber int) *TableDef {
 	newObj := new(TableDef)
 	newObj.Name = name
 	newObj.LineNumber = lineNumber
+	newObj.SourceFileNames = make([]string, 0)
 	newObj.Readers = make(map[string]*ReaderDef)
 	newObj.LocalStructs = make([]*StructDef, 0)
 	newObj.LocalStructNameIndex = make(map[string]*StructDef)
 	newObj.Columns = make([]*TableColumnDef, 0)
 	newObj.ColumnNameIndex = make(map[string]*TableColumnDef)
+	newObj.Checks = make([]*TableCheckDef, 0)
 
 	return newObj
 }
 
 func (this *TableDef) Close() {
+	if this.Checks != nil {
+		for _, def := range this.Checks {
+			def.Close()
+		}
+		clear(this.Checks)
+		this.Checks = nil
+	}
 	if this.ColumnNameIndex != nil {
 		clear(this.ColumnNameIndex)
 		this.ColumnNameIndex = nil
@@ -289,5 +488,8 @@ func (this *TableDef) Close() {
 		clear(this.Readers)
 		this.Readers = nil
 	}
+	this.SourceFileNames = nil
+	this.SetOrderColumn = nil
+	this.RowReadbyColumn = nil
 	this.TableKey = nil
 }
@@ -0,0 +1,270 @@
+package lib
+
+import (
+	"slices"
+	"strings"
+)
+
+// report define file problems that are not errors,
+// all diagnostics are warnings
+type TableLinter struct {
+	Diagnostics *DiagnosticList
+
+	descriptor *TableDescriptor
+}
+
+func NewTableLinter(descriptor *TableDescriptor) *TableLinter {
+	newObj := new(TableLinter)
+	newObj.Diagnostics = NewDiagnosticList()
+	newObj.descriptor = descriptor
+
+	return newObj
+}
+
+func (this *TableLinter) Close() {
+	if this.Diagnostics != nil {
+		this.Diagnostics.Close()
+		this.Diagnostics = nil
+	}
+	this.descriptor = nil
+}
+
+func (this *TableLinter) Lint() {
+	this.checkDuplicatedReadbyReaders()
+	this.checkUnusedReaders()
+	this.checkUnusedGlobalStructs()
+	this.checkShadowedStructs()
+	this.checkColumnNames()
+	this.checkReaderColumns()
+}
+
+func (this *TableLinter) addWarning(
+	code string, lineNumber int, format string, args ...any) {
+
+	this.Diagnostics.AddWarning(code,
+		this.descriptor.FilePath, lineNumber, 0, format, args...)
+}
+
+// readers in define order
+func (this *TableLinter) getSortedReaders() []*ReaderDef {
+	readers := make([]*ReaderDef, 0, len(this.descriptor.Readers))
+	for _, def := range this.descriptor.Readers {
+		readers = append(readers, def)
+	}
+	slices.SortFunc(readers, func(a, b *ReaderDef) int {
+		return a.LineNumber - b.LineNumber
+	})
+
+	return readers
+}
+
+// reader names listed by readby terms, groups are expanded
+// and each reader counts once in a group,
+// excluded terms and extended readers are not included
+func (this *TableLinter) getListedReaderNames(
+	readby string, visitedGroups map[string]bool) []string {
+
+	names := make([]string, 0)
+
+	for term := range strings.SplitSeq(readby, "|") {
+		if strings.HasPrefix(term, "!") {
+			continue
+		}
+		groupName, ok := strings.CutPrefix(term, "@")
+		if ok == false {
+			names = append(names, term)
+			continue
+		}
+
+		groupDef, ok := this.descriptor.ReaderGroups[groupName]
+		if ok == false || visitedGroups[groupName] {
+			continue
+		}
+		visitedGroups[groupName] = true
+		groupNames := this.getListedReaderNames(
+			groupDef.ReadersText, visitedGroups)
+		slices.Sort(groupNames)
+		names = append(names, slices.Compact(groupNames)...)
+		delete(visitedGroups, groupName)
+	}
+
+	return names
+}
+
+func (this *TableLinter) checkReadbyText(
+	readby string, lineNumber int, ownerDesc string) {
+
+	if readby == "" {
+		return
+	}
+
+	listed := make(map[string]bool)
+	reported := make(map[string]bool)
+	names := this.getListedReaderNames(readby, make(map[string]bool))
+	for _, name := range names {
+		if listed[name] == false {
+			listed[name] = true
+			continue
+		}
+		if reported[name] {
+			continue
+		}
+		reported[name] = true
+		this.addWarning("duplicated-readby-reader", lineNumber,
+			"%s `%s` lists reader `%s` more than once",
+			ownerDesc, readby, name)
+	}
+}
+
+func (this *TableLinter) checkDuplicatedReadbyReaders() {
+	groups := make([]*ReaderGroupDef, 0, len(this.descriptor.ReaderGroups))
+	for _, def := range this.descriptor.ReaderGroups {
+		groups = append(groups, def)
+	}
+	slices.SortFunc(groups, func(a, b *ReaderGroupDef) int {
+		return a.LineNumber - b.LineNumber
+	})
+	for _, def := range groups {
+		this.checkReadbyText(def.ReadersText, def.LineNumber,
+			"reader group `"+def.Name+"` readers")
+	}
+
+	for _, tableDef := range this.descriptor.Tables {
+		this.checkReadbyText(tableDef.ReadbyText, tableDef.LineNumber,
+			"table `"+tableDef.Name+"` readby")
+		for _, def := range tableDef.Columns {
+			this.checkReadbyText(def.ReadbyText, def.LineNumber,
+				"column `"+def.Name+"` readby")
+		}
+	}
+}
+
+func (this *TableLinter) addReferencedReaders(
+	readby string, referenced map[string]bool) {
+
+	if readby == "" {
+		return
+	}
+
+	// excluded terms reference the reader too
+	for term := range strings.SplitSeq(readby, "|") {
+		term = strings.TrimPrefix(term, "!")
+		readers, err := this.descriptor.ResolveReadby(term)
+		if err != nil {
+			continue
+		}
+		for name := range readers {
+			referenced[name] = true
+		}
+	}
+}
+
+func (this *TableLinter) checkUnusedReaders() {
+	referenced := make(map[string]bool)
+	for _, tableDef := range this.descriptor.Tables {
+		this.addReferencedReaders(tableDef.ReadbyText, referenced)
+		for _, def := range tableDef.Columns {
+			this.addReferencedReaders(def.ReadbyText, referenced)
+		}
+	}
+
+	for _, def := range this.getSortedReaders() {
+		if referenced[def.Name] {
+			continue
+		}
+		this.addWarning("unused-reader", def.LineNumber,
+			"reader `%s` is not referenced by any table or column",
+			def.Name)
+	}
+}
+
+func (this *TableLinter) checkUnusedGlobalStructs() {
+	used := make(map[*StructDef]bool)
+	for _, tableDef := range this.descriptor.Tables {
+		for _, def := range tableDef.Columns {
+			if def.RefStructDef != nil {
+				used[def.RefStructDef] = true
+			}
+		}
+	}
+
+	for _, def := range this.descriptor.GlobalStructs {
+		if used[def] {
+			continue
+		}
+		this.addWarning("unused-struct", def.LineNumber,
+			"global struct `%s` is not used by any table", def.Name)
+	}
+}
+
+func (this *TableLinter) checkShadowedStructs() {
+	for _, tableDef := range this.descriptor.Tables {
+		for _, def := range tableDef.LocalStructs {
+			if _, ok :=
+				this.descriptor.GlobalStructNameIndex[def.Name]; ok {
+				this.addWarning("shadowed-struct", def.LineNumber,
+					"local struct `%s` of table `%s` "+
+						"shadows global struct `%s`",
+					def.Name, tableDef.Name, def.Name)
+			}
+		}
+	}
+}
+
+func (this *TableLinter) checkColumnNames() {
+	for _, tableDef := range this.descriptor.Tables {
+		for _, def := range tableDef.Columns {
+			if g_isSnakeCaseNameRegexp.MatchString(def.Name) {
+				continue
+			}
+			this.addWarning("column-name-style", def.LineNumber,
+				"column `%s` of table `%s` is not snake_case",
+				def.Name, tableDef.Name)
+		}
+	}
+}
+
+// tables left with only the key column for a reader reading them
+func (this *TableLinter) checkReaderColumns() {
+	readers := this.getSortedReaders()
+
+	for _, tableDef := range this.descriptor.Tables {
+		columns := make([]*TableColumnDef, 0, len(tableDef.Columns))
+		for _, def := range tableDef.Columns {
+			if def == tableDef.TableKey ||
+				def == tableDef.RowReadbyColumn {
+				continue
+			}
+			columns = append(columns, def)
+		}
+		if len(columns) == 0 {
+			continue
+		}
+
+		for _, readerDef := range readers {
+			if len(tableDef.Readers) > 0 {
+				if _, ok := tableDef.Readers[readerDef.Name]; ok == false {
+					continue
+				}
+			}
+
+			hasColumn := false
+			for _, def := range columns {
+				if len(def.Readers) == 0 {
+					hasColumn = true
+					break
+				}
+				if _, ok := def.Readers[readerDef.Name]; ok {
+					hasColumn = true
+					break
+				}
+			}
+			if hasColumn {
+				continue
+			}
+			this.addWarning("empty-reader-table", tableDef.LineNumber,
+				"table `%s` has every column filtered out for reader `%s`",
+				tableDef.Name, readerDef.Name)
+		}
+	}
+}
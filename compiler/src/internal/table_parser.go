@@ -1,37 +1,46 @@
 package lib
 
 import (
-	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/antchfx/xmlquery"
 )
 
 type TableParser struct {
-	Descriptor *TableDescriptor
+	Descriptor  *TableDescriptor
+	Diagnostics *DiagnosticList
 }
 
 func NewTableParser() *TableParser {
 	newObj := new(TableParser)
+	newObj.Diagnostics = NewDiagnosticList()
 
 	return newObj
 }
 
 func (this *TableParser) Close() {
+	if this.Diagnostics != nil {
+		this.Diagnostics.Close()
+		this.Diagnostics = nil
+	}
 	if this.Descriptor != nil {
 		this.Descriptor.Close()
 		this.Descriptor = nil
 	}
 }
 
+// parse the define file and collect all errors into Diagnostics,
+// definitions with errors are kept when possible
+// so that later checks do not report errors caused by them
 func (this *TableParser) Parse(defineFilePath string) bool {
 	// get file full path
 	defineFileFullPath := UtilGetFullPath(defineFilePath)
 	if defineFileFullPath == "" {
-		fmt.Fprintf(os.Stderr,
-			"error: can not find define file `%s`\n",
-			defineFilePath)
+		this.Diagnostics.AddError("read-file-failed",
+			defineFilePath, 0, 0, "can not find define file")
 		return false
 	}
 
@@ -54,9 +63,9 @@ func (this *TableParser) Parse(defineFilePath string) bool {
 	if rootNode == nil ||
 		rootNode.Type != xmlquery.ElementNode ||
 		rootNode.Data != "define" {
-		fmt.Fprintf(os.Stderr,
-			"error:%s: root node must be `define` node\n",
-			this.Descriptor.FilePath)
+		this.Diagnostics.AddError("invalid-root-node",
+			this.Descriptor.FilePath, 0, 0,
+			"root node must be `define` node")
 		return false
 	}
 
@@ -64,9 +73,15 @@ func (this *TableParser) Parse(defineFilePath string) bool {
 	{
 		nodes := xmlquery.Find(rootNode, "/reader")
 		for _, node := range nodes {
-			if this.addReaderDef(node) == false {
-				return false
-			}
+			this.addReaderDef(node)
+		}
+	}
+
+	// parse reader groups
+	{
+		nodes := xmlquery.Find(rootNode, "/readergroup")
+		for _, node := range nodes {
+			this.addReaderGroupDef(node)
 		}
 	}
 
@@ -74,9 +89,7 @@ func (this *TableParser) Parse(defineFilePath string) bool {
 	{
 		nodes := xmlquery.Find(rootNode, "/struct")
 		for _, node := range nodes {
-			if this.addStructDef(nil, node) == false {
-				return false
-			}
+			this.addStructDef(nil, node)
 		}
 	}
 
@@ -84,13 +97,14 @@ func (this *TableParser) Parse(defineFilePath string) bool {
 	{
 		nodes := xmlquery.Find(rootNode, "/table")
 		for _, node := range nodes {
-			if this.addTableDef(node) == false {
-				return false
-			}
+			this.addTableDef(node)
 		}
 	}
 
-	return true
+	// resolve table refs
+	this.resolveTableRefs()
+
+	return this.Diagnostics.ErrorCount() == 0
 }
 
 func (this *TableParser) FilterByReader(reader string) bool {
@@ -99,9 +113,8 @@ func (this *TableParser) FilterByReader(reader string) bool {
 	}
 
 	if _, ok := this.Descriptor.Readers[reader]; ok == false {
-		fmt.Fprintf(os.Stderr,
-			"error: reader `%s` is not defined\n",
-			reader)
+		this.Diagnostics.AddError("undefined-reader", "", 0, 0,
+			"reader `%s` is not defined", reader)
 		return false
 	}
 
@@ -132,6 +145,8 @@ func (this *TableParser) FilterByReader(reader string) bool {
 			used := false
 			if columnDef == tableDef.TableKey {
 				used = true
+			} else if columnDef == tableDef.RowReadbyColumn {
+				used = false
 			} else if len(columnDef.Readers) == 0 {
 				used = true
 			} else {
@@ -147,7 +162,31 @@ func (this *TableParser) FilterByReader(reader string) bool {
 			}
 		}
 		tableDef.Columns = filteredColumns
+		tableDef.RowReadbyColumn = nil
+		if tableDef.SetOrderColumn != nil &&
+			slices.Contains(filteredColumns,
+				tableDef.SetOrderColumn) == false {
+			tableDef.SetOrderColumn = nil
+		}
 		this.calculateTableKeyColumnIndex(tableDef)
+
+		// remove checks using unread columns
+		filteredChecks := make([]*TableCheckDef, 0)
+		for _, checkDef := range tableDef.Checks {
+			used := true
+			for _, columnDef := range checkDef.Expr.Columns {
+				if slices.Contains(tableDef.Columns, columnDef) == false {
+					used = false
+					break
+				}
+			}
+			if used {
+				filteredChecks = append(filteredChecks, checkDef)
+			} else {
+				checkDef.Close()
+			}
+		}
+		tableDef.Checks = filteredChecks
 	}
 
 	// collect used structs
@@ -186,27 +225,71 @@ func (this *TableParser) FilterByReader(reader string) bool {
 		tableDef.LocalStructs = filteredLocalStructs
 	}
 
+	// remove refs to unread tables
+	for _, structDef := range this.Descriptor.GlobalStructs {
+		this.removeUnreadStructFieldRefs(structDef)
+	}
+	for _, tableDef := range this.Descriptor.Tables {
+		for _, structDef := range tableDef.LocalStructs {
+			this.removeUnreadStructFieldRefs(structDef)
+		}
+		for _, columnDef := range tableDef.Columns {
+			if columnDef.RefTableName == "" {
+				continue
+			}
+			refTableName := columnDef.RefTableName
+			if _, ok := this.Descriptor.TableNameIndex[refTableName]; ok == false {
+				columnDef.RefTableName = ""
+				columnDef.RefTableDef = nil
+			}
+		}
+	}
+
 	return true
 }
 
+func (this *TableParser) removeUnreadStructFieldRefs(structDef *StructDef) {
+	for _, def := range structDef.Fields {
+		if def.RefTableName == "" {
+			continue
+		}
+		if _, ok := this.Descriptor.TableNameIndex[def.RefTableName]; ok == false {
+			def.RefTableName = ""
+			def.RefTableDef = nil
+		}
+	}
+}
+
 func (this *TableParser) isStrValidVarName(str string) bool {
 	return g_isVarNameRegexp.MatchString(str)
 }
 
-func (this *TableParser) printLineError(
-	fileName string, lineNumber int, format string, args ...any) {
+func (this *TableParser) isStrValidFileName(str string) bool {
+	if str == "" {
+		return false
+	}
+	if _, err := filepath.Match(str, ""); err != nil {
+		return false
+	}
+
+	return true
+}
+
+func (this *TableParser) isStrGlobPattern(str string) bool {
+	return strings.ContainsAny(str, "*?[")
+}
+
+func (this *TableParser) addLineError(
+	code string, lineNumber int, format string, args ...any) {
 
-	fmt.Fprintf(os.Stderr,
-		"error:%s:%d: %s\n",
-		fileName, lineNumber,
-		fmt.Sprintf(format, args...))
+	this.Diagnostics.AddError(code,
+		this.Descriptor.FilePath, lineNumber, 0, format, args...)
 }
 
-func (this *TableParser) printNodeError(
-	node *xmlquery.Node, format string, args ...any) {
+func (this *TableParser) addNodeError(
+	code string, node *xmlquery.Node, format string, args ...any) {
 
-	this.printLineError(
-		this.Descriptor.FilePath, node.LineNumber, format, args...)
+	this.addLineError(code, node.LineNumber, format, args...)
 }
 
 func (this *TableParser) getNodeAttr(
@@ -224,9 +307,8 @@ func (this *TableParser) getNodeAttr(
 func (this *TableParser) loadDefineFile(filePath string) *xmlquery.Node {
 	fileBin, err := os.ReadFile(filePath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr,
-			"error: can not read define file `%s`: %s\n",
-			filePath, err.Error())
+		this.Diagnostics.AddError("read-file-failed", filePath, 0, 0,
+			"can not read define file: %s", err.Error())
 		return nil
 	}
 	fileText := string(fileBin)
@@ -236,9 +318,8 @@ func (this *TableParser) loadDefineFile(filePath string) *xmlquery.Node {
 			WithLineNumbers: true,
 		})
 	if err != nil {
-		fmt.Fprintf(os.Stderr,
-			"error: can not parse define file `%s`: %s\n",
-			filePath, err.Error())
+		this.Diagnostics.AddError("invalid-define-file", filePath, 0, 0,
+			"can not parse define file: %s", err.Error())
 		return nil
 	}
 
@@ -251,51 +332,123 @@ func (this *TableParser) addReaderDef(node *xmlquery.Node) bool {
 	{
 		attr := this.getNodeAttr(node, "name")
 		if attr == nil {
-			this.printNodeError(node,
+			this.addNodeError("missing-attribute", node,
 				"`reader` node must contain a `name` attribute")
 			return false
 		}
 		name = attr.Value
 	}
 	if this.isStrValidVarName(name) == false {
-		this.printNodeError(node,
+		this.addNodeError("invalid-attribute", node,
 			"`reader` node `name` attribute is invalid")
 		return false
 	}
 	if _, ok := this.Descriptor.Readers[name]; ok {
-		this.printNodeError(node,
+		this.addNodeError("duplicated-name", node,
 			"`reader` node `name` attribute duplicated")
 		return false
 	}
 
+	// errors below do not stop parsing the node
+	parseOk := true
+
+	// check extends attr
+	var baseReaderDef *ReaderDef = nil
+	{
+		attr := this.getNodeAttr(node, "extends")
+		if attr != nil {
+			readerDef, ok := this.Descriptor.Readers[attr.Value]
+			if ok {
+				baseReaderDef = readerDef
+			} else {
+				this.addNodeError("undefined-reader", node,
+					"reader `%s` is not defined", attr.Value)
+				parseOk = false
+			}
+		}
+	}
+
 	// check namespace attr
 	var namespaceStr string
 	{
 		attr := this.getNodeAttr(node, "namespace")
-		if attr == nil {
-			this.printNodeError(node,
+		if attr != nil {
+			namespaceStr = attr.Value
+		} else if baseReaderDef != nil {
+			namespaceStr = baseReaderDef.Namespace
+		} else {
+			this.addNodeError("missing-attribute", node,
 				"`reader` node must contain a `namespace` attribute")
-			return false
+			parseOk = false
 		}
-		namespaceStr = attr.Value
 	}
 
 	// check namespace parts
 	namespaceParts := strings.Split(namespaceStr, ".")
-	for _, part := range namespaceParts {
-		if this.isStrValidVarName(part) == false {
-			this.printNodeError(node,
-				"`reader` node `namespace` attribute is invalid")
-			return false
+	if parseOk {
+		for _, part := range namespaceParts {
+			if this.isStrValidVarName(part) == false {
+				this.addNodeError("invalid-attribute", node,
+					"`reader` node `namespace` attribute is invalid")
+				parseOk = false
+				break
+			}
 		}
 	}
 
 	def := NewReadDef(name, node.LineNumber)
+	def.BaseReaderRef = baseReaderDef
 	def.Namespace = namespaceStr
 	def.NamespaceParts = namespaceParts
 
 	this.Descriptor.Readers[def.Name] = def
 
+	return parseOk
+}
+
+func (this *TableParser) addReaderGroupDef(node *xmlquery.Node) bool {
+	// check name attr
+	var name string
+	{
+		attr := this.getNodeAttr(node, "name")
+		if attr == nil {
+			this.addNodeError("missing-attribute", node,
+				"`readergroup` node must contain a `name` attribute")
+			return false
+		}
+		name = attr.Value
+	}
+	if this.isStrValidVarName(name) == false {
+		this.addNodeError("invalid-attribute", node,
+			"`readergroup` node `name` attribute is invalid")
+		return false
+	}
+	if _, ok := this.Descriptor.ReaderGroups[name]; ok {
+		this.addNodeError("duplicated-name", node,
+			"`readergroup` node `name` attribute duplicated")
+		return false
+	}
+
+	def := NewReaderGroupDef(name, node.LineNumber)
+	this.Descriptor.ReaderGroups[def.Name] = def
+
+	// check readers attr
+	{
+		attr := this.getNodeAttr(node, "readers")
+		if attr == nil {
+			this.addNodeError("missing-attribute", node,
+				"`readergroup` node must contain a `readers` attribute")
+			return false
+		}
+		def.ReadersText = attr.Value
+		readers, err := this.Descriptor.ResolveReadby(attr.Value)
+		if err != nil {
+			this.addNodeError("invalid-readby", node, "%s", err.Error())
+			return false
+		}
+		def.Readers = readers
+	}
+
 	return true
 }
 
@@ -307,14 +460,14 @@ func (this *TableParser) addStructDef(
 	{
 		attr := this.getNodeAttr(node, "name")
 		if attr == nil {
-			this.printNodeError(node,
+			this.addNodeError("missing-attribute", node,
 				"`struct` node must contain a `name` attribute")
 			return false
 		}
 		name = attr.Value
 	}
 	if this.isStrValidVarName(name) == false {
-		this.printNodeError(node,
+		this.addNodeError("invalid-attribute", node,
 			"`struct` node `name` attribute is invalid")
 		return false
 	}
@@ -324,45 +477,65 @@ func (this *TableParser) addStructDef(
 			_, ok = this.Descriptor.TableNameIndex[name]
 		}
 		if ok {
-			this.printNodeError(node,
+			this.addNodeError("duplicated-name", node,
 				"`struct` node `name` attribute duplicated")
 			return false
 		}
 	} else {
 		if _, ok := tableDef.LocalStructNameIndex[name]; ok {
-			this.printNodeError(node,
+			this.addNodeError("duplicated-name", node,
 				"`struct` node `name` attribute duplicated")
 			return false
 		}
+	}
+
+	// errors below do not stop parsing the node
+	parseOk := true
+
+	if tableDef != nil {
 		if name == "Row" ||
 			name == "Rows" ||
 			name == "RowIndex" ||
 			name == "RowSet" ||
 			name == "RowSets" ||
 			name == "RowSetIndex" {
-			this.printNodeError(node, ""+
+			this.addNodeError("reserved-name", node, ""+
 				"local struct can not be named as "+
 				"`Row`, `Rows`, `RowIndex`, "+
 				"`RowSet`, `RowSets` or `RowSetIndex`")
-			return false
+			parseOk = false
 		}
+	} else if name == "TableBundle" {
+		// generated cpp code of the reader bundle is named as it
+		this.addNodeError("reserved-name", node,
+			"global struct can not be named as `TableBundle`")
+		parseOk = false
 	}
 
 	def := NewStructDef(tableDef, name, node.LineNumber)
 
+	// check desc attr
+	{
+		attr := this.getNodeAttr(node, "desc")
+		if attr != nil {
+			def.Description = attr.Value
+		}
+	}
+
 	// parse fields
 	for _, childNode := range node.ChildNodes() {
 		if childNode.Type != xmlquery.ElementNode {
 			continue
 		}
 		if childNode.Data != "field" {
-			this.printNodeError(childNode,
+			this.addNodeError("unexpected-node", childNode,
 				"expect a `field` node")
-			return false
+			parseOk = false
+			continue
 		}
 
 		if this.addStructFieldDef(def, childNode) == false {
-			return false
+			parseOk = false
 		}
 	}
 
@@ -375,7 +548,7 @@ func (this *TableParser) addStructDef(
 		tableDef.LocalStructNameIndex[def.Name] = def
 	}
 
-	return true
+	return parseOk
 }
 
 func (this *TableParser) addStructFieldDef(
@@ -386,19 +559,19 @@ func (this *TableParser) addStructFieldDef(
 	{
 		attr := this.getNodeAttr(node, "name")
 		if attr == nil {
-			this.printNodeError(node,
+			this.addNodeError("missing-attribute", node,
 				"`field` node must contain a `name` attribute")
 			return false
 		}
 		name = attr.Value
 	}
 	if this.isStrValidVarName(name) == false {
-		this.printNodeError(node,
+		this.addNodeError("invalid-attribute", node,
 			"`field` node `name` attribute is invalid")
 		return false
 	}
 	if _, ok := structDef.FieldNameIndex[name]; ok {
-		this.printNodeError(node,
+		this.addNodeError("duplicated-name", node,
 			"`field` node `name` attribute duplicated")
 		return false
 	}
@@ -408,7 +581,7 @@ func (this *TableParser) addStructFieldDef(
 	{
 		attr := this.getNodeAttr(node, "type")
 		if attr == nil {
-			this.printNodeError(node,
+			this.addNodeError("missing-attribute", node,
 				"`field` node must contain a `type` attribute")
 			return false
 		}
@@ -416,21 +589,45 @@ func (this *TableParser) addStructFieldDef(
 	}
 
 	def := NewStructFieldDef(structDef, name, node.LineNumber)
+	structDef.Fields = append(structDef.Fields, def)
+	structDef.FieldNameIndex[def.Name] = def
+
+	// errors below do not stop parsing the node
+	parseOk := true
+
+	// check desc attr
+	{
+		attr := this.getNodeAttr(node, "desc")
+		if attr != nil {
+			def.Description = attr.Value
+		}
+	}
 
 	if typ == "int" {
 		def.Type = StructFieldType_Int
 	} else if typ == "string" {
 		def.Type = StructFieldType_String
 	} else {
-		this.printNodeError(node,
+		this.addNodeError("invalid-type", node,
 			"type `%s` is invalid", typ)
-		return false
+		parseOk = false
 	}
 
-	structDef.Fields = append(structDef.Fields, def)
-	structDef.FieldNameIndex[def.Name] = def
+	// check ref attr
+	{
+		attr := this.getNodeAttr(node, "ref")
+		if attr != nil {
+			if this.isStrValidVarName(attr.Value) {
+				def.RefTableName = attr.Value
+			} else {
+				this.addNodeError("invalid-attribute", node,
+					"`field` node `ref` attribute is invalid")
+				parseOk = false
+			}
+		}
+	}
 
-	return true
+	return parseOk
 }
 
 func (this *TableParser) addTableDef(node *xmlquery.Node) bool {
@@ -439,14 +636,14 @@ func (this *TableParser) addTableDef(node *xmlquery.Node) bool {
 	{
 		attr := this.getNodeAttr(node, "name")
 		if attr == nil {
-			this.printNodeError(node,
+			this.addNodeError("missing-attribute", node,
 				"`table` node must contain a `name` attribute")
 			return false
 		}
 		name = attr.Value
 	}
 	if this.isStrValidVarName(name) == false {
-		this.printNodeError(node,
+		this.addNodeError("invalid-attribute", node,
 			"`table` node `name` attribute is invalid")
 		return false
 	}
@@ -456,12 +653,35 @@ func (this *TableParser) addTableDef(node *xmlquery.Node) bool {
 		_, ok = this.Descriptor.GlobalStructNameIndex[name]
 	}
 	if ok {
-		this.printNodeError(node,
+		this.addNodeError("duplicated-name", node,
 			"`table` node `name` attribute duplicated")
 		return false
 	}
 
 	def := NewTableDef(name, node.LineNumber)
+	this.Descriptor.Tables = append(this.Descriptor.Tables, def)
+	this.Descriptor.TableNameIndex[def.Name] = def
+
+	// errors below do not stop parsing the node
+	parseOk := true
+
+	if name == "TableBundle" {
+		// generated cpp code of the reader bundle is named as it
+		this.addNodeError("reserved-name", node,
+			"table can not be named as `TableBundle`")
+		parseOk = false
+	}
+
+	// check desc attr
+	{
+		attr := this.getNodeAttr(node, "desc")
+		if attr != nil {
+			def.Description = attr.Value
+		}
+	}
+
+	// checks are compiled after all columns are added
+	checkNodes := make([]*xmlquery.Node, 0)
 
 	for _, childNode := range node.ChildNodes() {
 		if childNode.Type != xmlquery.ElementNode {
@@ -471,21 +691,65 @@ func (this *TableParser) addTableDef(node *xmlquery.Node) bool {
 		if childNode.Data == "struct" {
 			// parse local struct
 			if this.addStructDef(def, childNode) == false {
-				return false
+				parseOk = false
 			}
 		} else if childNode.Data == "col" {
 			// parse column
 			if this.addTableColumnDef(def, childNode) == false {
-				return false
+				parseOk = false
 			}
+		} else if childNode.Data == "file" {
+			// parse source file
+			if this.addTableSourceFileName(def, childNode) == false {
+				parseOk = false
+			}
+		} else if childNode.Data == "check" {
+			checkNodes = append(checkNodes, childNode)
 		} else {
-			this.printNodeError(childNode,
-				"expect a `struct` or `col` node")
+			this.addNodeError("unexpected-node", childNode,
+				"expect a `struct`, `col`, `file` or `check` node")
+			parseOk = false
 		}
 	}
 
-	// check key/setkey attr
+	// parse row check
+	for _, childNode := range checkNodes {
+		if this.addTableCheckDef(def, childNode) == false {
+			parseOk = false
+		}
+	}
+
+	// check kind attr
 	{
+		attr := this.getNodeAttr(node, "kind")
+		if attr == nil || attr.Value == "normal" {
+			def.TableKind = TableKind_Normal
+		} else if attr.Value == "config" {
+			def.TableKind = TableKind_Config
+		} else {
+			this.addNodeError("invalid-attribute", node,
+				"table kind `%s` is invalid", attr.Value)
+			def.TableKind = TableKind_Normal
+			parseOk = false
+		}
+	}
+
+	// check key/setkey attr
+	if def.TableKind == TableKind_Config {
+		if this.getNodeAttr(node, "key") != nil ||
+			this.getNodeAttr(node, "setkey") != nil {
+			this.addNodeError("conflicting-attribute", node, ""+
+				"config table can not contain "+
+				"a `key` or `setkey` attribute")
+			parseOk = false
+		}
+		if len(def.Columns) == 0 {
+			this.addNodeError("missing-column", node,
+				"config table must contain at least one `col` node")
+			parseOk = false
+		}
+		def.TableKeyType = TableKeyType_None
+	} else {
 		var key string
 		attr := this.getNodeAttr(node, "key")
 		if attr != nil {
@@ -497,57 +761,226 @@ func (this *TableParser) addTableDef(node *xmlquery.Node) bool {
 				key = attr.Value
 				def.TableKeyType = TableKeyType_SetKey
 			} else {
-				this.printNodeError(node,
+				this.addNodeError("missing-attribute", node,
 					"`table` node must contain a `key` or `setkey` attribute")
-				return false
+				parseOk = false
 			}
 		}
 
-		tableKey, ok := def.ColumnNameIndex[key]
-		if ok == false {
-			this.printNodeError(node,
-				"table key `%s` is not defined", key)
-			return false
-		}
-		if tableKey.Type != TableColumnType_Int &&
-			tableKey.Type != TableColumnType_String {
-			this.printNodeError(node,
-				"table key can only be `int` or `string` type")
-			return false
+		if attr != nil {
+			tableKey, ok := def.ColumnNameIndex[key]
+			if ok == false {
+				this.addNodeError("undefined-column", node,
+					"table key `%s` is not defined", key)
+				parseOk = false
+			} else if tableKey.Type == TableColumnType_None {
+				// column type error is already reported
+				parseOk = false
+			} else if tableKey.Type != TableColumnType_Int &&
+				tableKey.Type != TableColumnType_String {
+				this.addNodeError("invalid-key-type", node,
+					"table key can only be `int` or `string` type")
+				parseOk = false
+			} else {
+				def.TableKey = tableKey
+			}
 		}
-		def.TableKey = tableKey
 	}
 
 	// check file attr
 	{
 		attr := this.getNodeAttr(node, "file")
-		if attr == nil {
-			this.printNodeError(node,
-				"`table` node must contain a `file` attribute")
-			return false
+		if attr != nil {
+			if this.isStrValidFileName(attr.Value) {
+				def.SourceFileNames = slices.Insert(
+					def.SourceFileNames, 0, attr.Value)
+			} else {
+				this.addNodeError("invalid-attribute", node,
+					"`table` node `file` attribute is invalid")
+				parseOk = false
+			}
+		} else if len(def.SourceFileNames) == 0 {
+			this.addNodeError("missing-attribute", node, ""+
+				"`table` node must contain a `file` attribute "+
+				"or `file` nodes")
+			parseOk = false
+		}
+	}
+
+	// check outfile attr
+	{
+		attr := this.getNodeAttr(node, "outfile")
+		if attr != nil {
+			if this.isStrValidFileName(attr.Value) == false ||
+				this.isStrGlobPattern(attr.Value) {
+				this.addNodeError("invalid-attribute", node,
+					"`table` node `outfile` attribute is invalid")
+				parseOk = false
+			} else {
+				def.FileName = attr.Value
+			}
+		} else if len(def.SourceFileNames) == 1 &&
+			this.isStrGlobPattern(def.SourceFileNames[0]) == false {
+			def.FileName = def.SourceFileNames[0]
+		} else if len(def.SourceFileNames) > 0 {
+			this.addNodeError("missing-attribute", node, ""+
+				"`table` node must contain an `outfile` attribute "+
+				"when it has multiple data files")
+			parseOk = false
 		}
-		def.FileName = attr.Value
 	}
 
 	// check readby attr
 	{
 		attr := this.getNodeAttr(node, "readby")
 		if attr != nil {
-			for reader := range strings.SplitSeq(attr.Value, "|") {
-				readerDef, ok := this.Descriptor.Readers[reader]
-				if ok == false {
-					this.printNodeError(node,
-						"reader `%s` is not defined", reader)
-					return false
+			def.ReadbyText = attr.Value
+			readers, err := this.Descriptor.ResolveReadby(attr.Value)
+			if err != nil {
+				this.addNodeError("invalid-readby", node, "%s", err.Error())
+				parseOk = false
+			} else {
+				def.Readers = readers
+			}
+		}
+	}
+
+	// check rowreadby attr
+	{
+		attr := this.getNodeAttr(node, "rowreadby")
+		if attr != nil {
+			columnDef, ok := def.ColumnNameIndex[attr.Value]
+			if def.TableKind == TableKind_Config {
+				this.addNodeError("conflicting-attribute", node,
+					"config table can not contain a `rowreadby` attribute")
+				parseOk = false
+			} else if ok == false {
+				this.addNodeError("undefined-column", node,
+					"row readby column `%s` is not defined", attr.Value)
+				parseOk = false
+			} else if columnDef == def.TableKey {
+				this.addNodeError("invalid-row-readby", node,
+					"row readby column can not be the table key")
+				parseOk = false
+			} else if columnDef.Type != TableColumnType_String {
+				if columnDef.Type != TableColumnType_None {
+					this.addNodeError("invalid-row-readby", node,
+						"row readby column can only be `string` type")
+				}
+				parseOk = false
+			} else {
+				def.RowReadbyColumn = columnDef
+			}
+		}
+	}
+
+	// check setorder attr
+	{
+		attr := this.getNodeAttr(node, "setorder")
+		if attr != nil {
+			columnDef, ok := def.ColumnNameIndex[attr.Value]
+			if def.TableKeyType != TableKeyType_SetKey {
+				// missing key error of normal table is already reported
+				if def.TableKind == TableKind_Config ||
+					def.TableKeyType != TableKeyType_None {
+					this.addNodeError("conflicting-attribute", node,
+						"only setkey table can contain a `setorder` attribute")
+				}
+				parseOk = false
+			} else if ok == false {
+				this.addNodeError("undefined-column", node,
+					"set order column `%s` is not defined", attr.Value)
+				parseOk = false
+			} else if columnDef == def.TableKey ||
+				columnDef == def.RowReadbyColumn {
+				this.addNodeError("invalid-set-order", node,
+					"set order column can not be "+
+						"the table key or row readby column")
+				parseOk = false
+			} else if columnDef.Type != TableColumnType_Int &&
+				columnDef.Type != TableColumnType_String {
+				if columnDef.Type != TableColumnType_None {
+					this.addNodeError("invalid-set-order", node,
+						"set order column can only be `int` or `string` type")
 				}
-				def.Readers[reader] = readerDef
+				parseOk = false
+			} else {
+				def.SetOrderColumn = columnDef
 			}
 		}
 	}
 
 	this.calculateTableKeyColumnIndex(def)
-	this.Descriptor.Tables = append(this.Descriptor.Tables, def)
-	this.Descriptor.TableNameIndex[def.Name] = def
+
+	return parseOk
+}
+
+func (this *TableParser) addTableSourceFileName(
+	tableDef *TableDef, node *xmlquery.Node) bool {
+
+	// check name attr
+	var name string
+	{
+		attr := this.getNodeAttr(node, "name")
+		if attr == nil {
+			this.addNodeError("missing-attribute", node,
+				"`file` node must contain a `name` attribute")
+			return false
+		}
+		name = attr.Value
+	}
+	if this.isStrValidFileName(name) == false {
+		this.addNodeError("invalid-attribute", node,
+			"`file` node `name` attribute is invalid")
+		return false
+	}
+	if slices.Contains(tableDef.SourceFileNames, name) {
+		this.addNodeError("duplicated-name", node,
+			"`file` node `name` attribute duplicated")
+		return false
+	}
+
+	tableDef.SourceFileNames = append(tableDef.SourceFileNames, name)
+
+	return true
+}
+
+func (this *TableParser) addTableCheckDef(
+	tableDef *TableDef, node *xmlquery.Node) bool {
+
+	// check expr attr
+	var exprText string
+	{
+		attr := this.getNodeAttr(node, "expr")
+		if attr == nil {
+			this.addNodeError("missing-attribute", node,
+				"`check` node must contain an `expr` attribute")
+			return false
+		}
+		exprText = attr.Value
+	}
+
+	expr, err := CompileTableCheckExpr(tableDef, exprText)
+	if err == errTableCheckExprInvalidType {
+		// column type error is already reported
+		return false
+	} else if err != nil {
+		this.addNodeError("invalid-check", node,
+			"check `%s` is invalid: %s", exprText, err.Error())
+		return false
+	}
+
+	def := NewTableCheckDef(tableDef, node.LineNumber)
+	def.Expr = expr
+	tableDef.Checks = append(tableDef.Checks, def)
+
+	// check message attr
+	{
+		attr := this.getNodeAttr(node, "message")
+		if attr != nil {
+			def.Message = attr.Value
+		}
+	}
 
 	return true
 }
@@ -560,19 +993,19 @@ func (this *TableParser) addTableColumnDef(
 	{
 		attr := this.getNodeAttr(node, "name")
 		if attr == nil {
-			this.printNodeError(node,
+			this.addNodeError("missing-attribute", node,
 				"`col` node must contain a `name` attribute")
 			return false
 		}
 		name = attr.Value
 	}
 	if this.isStrValidVarName(name) == false {
-		this.printNodeError(node,
+		this.addNodeError("invalid-attribute", node,
 			"`col` node `name` attribute is invalid")
 		return false
 	}
 	if _, ok := tableDef.ColumnNameIndex[name]; ok {
-		this.printNodeError(node,
+		this.addNodeError("duplicated-name", node,
 			"`col` node `name` attribute duplicated")
 		return false
 	}
@@ -582,7 +1015,7 @@ func (this *TableParser) addTableColumnDef(
 	{
 		attr := this.getNodeAttr(node, "type")
 		if attr == nil {
-			this.printNodeError(node,
+			this.addNodeError("missing-attribute", node,
 				"`col` node must contain a `type` attribute")
 			return false
 		}
@@ -590,6 +1023,21 @@ func (this *TableParser) addTableColumnDef(
 	}
 
 	def := NewTableColumnDef(tableDef, name, node.LineNumber)
+	def.TypeName = typ
+	tableDef.Columns = append(tableDef.Columns, def)
+	tableDef.ColumnNameIndex[def.Name] = def
+
+	// errors below do not stop parsing the node,
+	// column type is left as none when type is invalid
+	parseOk := true
+
+	// check desc attr
+	{
+		attr := this.getNodeAttr(node, "desc")
+		if attr != nil {
+			def.Description = attr.Value
+		}
+	}
 
 	// get type info
 	columnTypeStr := typ
@@ -618,13 +1066,15 @@ func (this *TableParser) addTableColumnDef(
 			columnType = TableColumnType_Struct
 			def.RefStructDef = refStructDef
 		} else {
-			this.printNodeError(node,
+			this.addNodeError("invalid-type", node,
 				"type `%s` is invalid", typ)
-			return false
+			parseOk = false
 		}
 	}
 
-	if def.Type == TableColumnType_List {
+	if columnType == TableColumnType_None {
+		def.Type = TableColumnType_None
+	} else if def.Type == TableColumnType_List {
 		def.ListType = columnType
 	} else {
 		def.Type = columnType
@@ -634,22 +1084,130 @@ func (this *TableParser) addTableColumnDef(
 	{
 		attr := this.getNodeAttr(node, "readby")
 		if attr != nil {
-			for reader := range strings.SplitSeq(attr.Value, "|") {
-				readerDef, ok := this.Descriptor.Readers[reader]
-				if ok == false {
-					this.printNodeError(node,
-						"reader `%s` is not defined", reader)
-					return false
-				}
-				def.Readers[reader] = readerDef
+			def.ReadbyText = attr.Value
+			readers, err := this.Descriptor.ResolveReadby(attr.Value)
+			if err != nil {
+				this.addNodeError("invalid-readby", node, "%s", err.Error())
+				parseOk = false
+			} else {
+				def.Readers = readers
 			}
 		}
 	}
 
-	tableDef.Columns = append(tableDef.Columns, def)
-	tableDef.ColumnNameIndex[def.Name] = def
+	// check ref attr
+	{
+		attr := this.getNodeAttr(node, "ref")
+		if attr != nil {
+			if this.isStrValidVarName(attr.Value) == false {
+				this.addNodeError("invalid-attribute", node,
+					"`col` node `ref` attribute is invalid")
+				parseOk = false
+			} else if columnType == TableColumnType_Struct {
+				this.addNodeError("conflicting-attribute", node, ""+
+					"`col` node `ref` attribute is not allowed "+
+					"on struct column, use it on struct field")
+				parseOk = false
+			} else {
+				def.RefTableName = attr.Value
+			}
+		}
+	}
 
-	return true
+	return parseOk
+}
+
+func (this *TableParser) resolveTableRefs() bool {
+	resolveOk := true
+
+	for _, structDef := range this.Descriptor.GlobalStructs {
+		if this.resolveStructFieldRefs(structDef) == false {
+			resolveOk = false
+		}
+	}
+
+	for _, tableDef := range this.Descriptor.Tables {
+		for _, structDef := range tableDef.LocalStructs {
+			if this.resolveStructFieldRefs(structDef) == false {
+				resolveOk = false
+			}
+		}
+		for _, def := range tableDef.Columns {
+			if def.RefTableName == "" ||
+				def.Type == TableColumnType_None {
+				continue
+			}
+
+			keyType := def.Type
+			if keyType == TableColumnType_List {
+				keyType = def.ListType
+			}
+			refTableDef := this.getRefTableDef(
+				def.RefTableName, def.LineNumber, keyType)
+			if refTableDef == nil {
+				resolveOk = false
+				continue
+			}
+			def.RefTableDef = refTableDef
+		}
+	}
+
+	return resolveOk
+}
+
+func (this *TableParser) resolveStructFieldRefs(structDef *StructDef) bool {
+	resolveOk := true
+
+	for _, def := range structDef.Fields {
+		if def.RefTableName == "" ||
+			def.Type == StructFieldType_None {
+			continue
+		}
+
+		keyType := TableColumnType_String
+		if def.Type == StructFieldType_Int {
+			keyType = TableColumnType_Int
+		}
+		refTableDef := this.getRefTableDef(
+			def.RefTableName, def.LineNumber, keyType)
+		if refTableDef == nil {
+			resolveOk = false
+			continue
+		}
+		def.RefTableDef = refTableDef
+	}
+
+	return resolveOk
+}
+
+// return nil when ref table is invalid,
+// tables with key errors are not reported again
+func (this *TableParser) getRefTableDef(refTableName string,
+	lineNumber int, keyType TableColumnType) *TableDef {
+
+	refTableDef, ok := this.Descriptor.TableNameIndex[refTableName]
+	if ok == false {
+		this.addLineError("undefined-table", lineNumber,
+			"ref table `%s` is not defined", refTableName)
+		return nil
+	}
+	if refTableDef.TableKind == TableKind_Config {
+		this.addLineError("invalid-ref", lineNumber,
+			"ref table `%s` is config table and has no key",
+			refTableName)
+		return nil
+	}
+	if refTableDef.TableKey == nil {
+		return nil
+	}
+	if refTableDef.TableKey.Type != keyType {
+		this.addLineError("invalid-ref", lineNumber,
+			"ref table `%s` key type `%s` does not match",
+			refTableName, refTableDef.TableKey.TypeName)
+		return nil
+	}
+
+	return refTableDef
 }
 
 func (this *TableParser) calculateTableKeyColumnIndex(tableDef *TableDef) {
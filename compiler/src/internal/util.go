@@ -1,7 +1,6 @@
 package lib
 
 import (
-	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -41,37 +40,27 @@ func UtilCheckDirExists(dirPath string) bool {
 	return true
 }
 
-func UtilReadAllTextShared(filePath string) (string, bool) {
+func UtilReadAllBytesShared(filePath string) ([]byte, error) {
 	file, err := UtilOpenFileShared(filePath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr,
-			"error: open file %s failed: %s\n",
-			filePath, err.Error())
-		return "", false
+		return nil, err
 	}
 	defer file.Close()
 
-	fileBytes, err := io.ReadAll(file)
-	if err != nil {
-		fmt.Fprintf(os.Stderr,
-			"error: read file %s failed: %s\n",
-			filePath, err.Error())
-		return "", false
-	}
-
-	return string(fileBytes), true
+	return io.ReadAll(file)
 }
 
-func UtilWriteAllText(filePath string, fileContent string) bool {
-	err := os.WriteFile(filePath, []byte(fileContent), 0644)
+func UtilReadAllTextShared(filePath string) (string, error) {
+	fileBytes, err := UtilReadAllBytesShared(filePath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr,
-			"error: write file %s failed: %s\n",
-			filePath, err.Error())
-		return false
+		return "", err
 	}
 
-	return true
+	return string(fileBytes), nil
+}
+
+func UtilWriteAllText(filePath string, fileContent string) error {
+	return os.WriteFile(filePath, []byte(fileContent), 0644)
 }
 
 func UtilCamelToUnderscore(camelName string) string {
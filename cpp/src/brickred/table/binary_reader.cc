@@ -0,0 +1,188 @@
+#include <brickred/table/binary_reader.h>
+
+#include <cstring>
+
+#include <brickred/table/util.h>
+
+namespace brickred::table {
+
+static const char s_magic[] = "BRTB";
+static const uint64_t s_version = 1;
+
+BinaryReader::BinaryReader(const std::string &data) :
+    data_(data), read_index_(0)
+{
+}
+
+BinaryReader::~BinaryReader()
+{
+}
+
+bool BinaryReader::readHeader(const char *schema, std::string *error_info)
+{
+    size_t magic_size = sizeof(s_magic) - 1;
+    if (data_.size() < magic_size ||
+        ::memcmp(data_.data(), s_magic, magic_size) != 0) {
+        *error_info = "binary magic is invalid";
+        return false;
+    }
+    read_index_ = magic_size;
+
+    uint64_t version = 0;
+    if (readVarint(&version) == false) {
+        *error_info = "binary version is invalid";
+        return false;
+    }
+    if (version != s_version) {
+        *error_info = util::error(
+            "binary version %llu is not supported",
+            (unsigned long long)version);
+        return false;
+    }
+
+    std::string data_schema;
+    if (readRawString(&data_schema) == false) {
+        *error_info = "binary schema is invalid";
+        return false;
+    }
+    if (data_schema != schema) {
+        *error_info = util::error(
+            "binary schema `%s` does not match `%s`",
+            data_schema.c_str(), schema);
+        return false;
+    }
+
+    size_t string_count = 0;
+    if (readSize(&string_count) == false) {
+        *error_info = "binary string pool is invalid";
+        return false;
+    }
+    string_pool_.clear();
+    for (size_t i = 0; i < string_count; ++i) {
+        std::string &str = string_pool_.emplace_back();
+        if (readRawString(&str) == false) {
+            *error_info = "binary string pool is invalid";
+            return false;
+        }
+    }
+
+    return true;
+}
+
+bool BinaryReader::readSize(size_t *value)
+{
+    uint64_t v = 0;
+    if (readVarint(&v) == false) {
+        return false;
+    }
+    // size can not be larger than the data
+    if (v > data_.size()) {
+        return false;
+    }
+    *value = (size_t)v;
+
+    return true;
+}
+
+bool BinaryReader::readInt(int32_t *value)
+{
+    uint64_t v = 0;
+    if (readVarint(&v) == false) {
+        return false;
+    }
+    // zigzag decode
+    int64_t i = (int64_t)(v >> 1) ^ -(int64_t)(v & 1);
+    if (i < INT32_MIN || i > INT32_MAX) {
+        return false;
+    }
+    *value = (int32_t)i;
+
+    return true;
+}
+
+bool BinaryReader::readString(std::string *value)
+{
+    size_t index = 0;
+    if (readSize(&index) == false) {
+        return false;
+    }
+    if (index >= string_pool_.size()) {
+        return false;
+    }
+    *value = string_pool_[index];
+
+    return true;
+}
+
+bool BinaryReader::readIntList(std::vector<int32_t> *value)
+{
+    size_t count = 0;
+    if (readSize(&count) == false) {
+        return false;
+    }
+
+    value->clear();
+    for (size_t i = 0; i < count; ++i) {
+        int32_t v = 0;
+        if (readInt(&v) == false) {
+            return false;
+        }
+        value->push_back(v);
+    }
+
+    return true;
+}
+
+bool BinaryReader::readStringList(std::vector<std::string> *value)
+{
+    size_t count = 0;
+    if (readSize(&count) == false) {
+        return false;
+    }
+
+    value->clear();
+    for (size_t i = 0; i < count; ++i) {
+        std::string &v = value->emplace_back();
+        if (readString(&v) == false) {
+            return false;
+        }
+    }
+
+    return true;
+}
+
+bool BinaryReader::readVarint(uint64_t *value)
+{
+    uint64_t v = 0;
+
+    for (int shift = 0; shift < 64; shift += 7) {
+        if (read_index_ >= data_.size()) {
+            return false;
+        }
+        uint8_t b = (uint8_t)data_[read_index_++];
+        v |= (uint64_t)(b & 0x7f) << shift;
+        if ((b & 0x80) == 0) {
+            *value = v;
+            return true;
+        }
+    }
+
+    return false;
+}
+
+bool BinaryReader::readRawString(std::string *value)
+{
+    size_t size = 0;
+    if (readSize(&size) == false) {
+        return false;
+    }
+    if (size > data_.size() - read_index_) {
+        return false;
+    }
+    value->assign(data_, read_index_, size);
+    read_index_ += size;
+
+    return true;
+}
+
+} // namespace brickred::table
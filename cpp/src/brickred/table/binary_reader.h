@@ -0,0 +1,61 @@
+#ifndef BRICKRED_TABLE_BINARY_READER_H
+#define BRICKRED_TABLE_BINARY_READER_H
+
+#include <cstddef>
+#include <cstdint>
+#include <string>
+#include <vector>
+
+namespace brickred::table {
+
+class BinaryReader final {
+public:
+    // only save the reference of the string
+    // be attention with string lifetime
+    BinaryReader(const std::string &data);
+    ~BinaryReader();
+
+    // check magic, version and schema, then read string pool
+    bool readHeader(const char *schema, std::string *error_info);
+    bool readSize(size_t *value);
+    bool readInt(int32_t *value);
+    bool readString(std::string *value);
+    bool readIntList(std::vector<int32_t> *value);
+    bool readStringList(std::vector<std::string> *value);
+    template <class T>
+    bool readStructList(std::vector<T> *value);
+    bool atEnd() const { return read_index_ == data_.size(); }
+
+private:
+    bool readVarint(uint64_t *value);
+    bool readRawString(std::string *value);
+
+private:
+    const std::string &data_;
+    size_t read_index_;
+    std::vector<std::string> string_pool_;
+};
+
+template <class T>
+bool BinaryReader::readStructList(std::vector<T> *value)
+{
+    size_t count = 0;
+    if (readSize(&count) == false) {
+        return false;
+    }
+
+    value->clear();
+    for (size_t i = 0; i < count; ++i) {
+        T v;
+        if (v.parseBinary(this) == false) {
+            return false;
+        }
+        value->push_back(v);
+    }
+
+    return true;
+}
+
+} // namespace brickred::table
+
+#endif
@@ -0,0 +1,177 @@
+#include <brickred/table/bundle_reader.h>
+
+#include <cstring>
+#include <zlib.h>
+
+#include <brickred/table/util.h>
+
+namespace brickred::table {
+
+static const char s_magic[] = "BRTP";
+static const uint32_t s_version = 1;
+static const uint32_t s_entry_flag_deflate = 0x1;
+
+static bool readUint16(const std::string &data, size_t *index,
+    uint16_t *value)
+{
+    if (data.size() - *index < 2) {
+        return false;
+    }
+    const unsigned char *p =
+        (const unsigned char *)data.data() + *index;
+    *value = (uint16_t)(p[0] | (p[1] << 8));
+    *index += 2;
+
+    return true;
+}
+
+static bool readUint32(const std::string &data, size_t *index,
+    uint32_t *value)
+{
+    if (data.size() - *index < 4) {
+        return false;
+    }
+    const unsigned char *p =
+        (const unsigned char *)data.data() + *index;
+    *value = (uint32_t)p[0] | ((uint32_t)p[1] << 8) |
+        ((uint32_t)p[2] << 16) | ((uint32_t)p[3] << 24);
+    *index += 4;
+
+    return true;
+}
+
+BundleReader::BundleReader() :
+    data_(nullptr), schema_fingerprint_(0)
+{
+}
+
+BundleReader::~BundleReader()
+{
+}
+
+bool BundleReader::open(const std::string &data,
+    uint32_t schema_fingerprint, std::string *error_info)
+{
+    data_ = nullptr;
+    schema_fingerprint_ = 0;
+    entry_index_.clear();
+
+    size_t magic_size = sizeof(s_magic) - 1;
+    if (data.size() < magic_size ||
+        ::memcmp(data.data(), s_magic, magic_size) != 0) {
+        *error_info = "bundle magic is invalid";
+        return false;
+    }
+    size_t index = magic_size;
+
+    uint32_t version = 0;
+    if (readUint32(data, &index, &version) == false) {
+        *error_info = "bundle version is invalid";
+        return false;
+    }
+    if (version != s_version) {
+        *error_info = util::error(
+            "bundle version %u is not supported", version);
+        return false;
+    }
+
+    uint32_t entry_count = 0;
+    if (readUint32(data, &index, &schema_fingerprint_) == false ||
+        readUint32(data, &index, &entry_count) == false) {
+        *error_info = "bundle header is invalid";
+        return false;
+    }
+    if (schema_fingerprint_ != schema_fingerprint) {
+        *error_info = util::error(
+            "bundle schema fingerprint %08x does not match %08x",
+            schema_fingerprint_, schema_fingerprint);
+        return false;
+    }
+
+    for (uint32_t i = 0; i < entry_count; ++i) {
+        uint16_t name_size = 0;
+        if (readUint16(data, &index, &name_size) == false ||
+            data.size() - index < name_size) {
+            *error_info = "bundle table of contents is invalid";
+            return false;
+        }
+        std::string name(data, index, name_size);
+        index += name_size;
+
+        Entry entry;
+        if (readUint32(data, &index, &entry.flags) == false ||
+            readUint32(data, &index, &entry.offset) == false ||
+            readUint32(data, &index, &entry.size) == false ||
+            readUint32(data, &index, &entry.raw_size) == false ||
+            readUint32(data, &index, &entry.crc) == false) {
+            *error_info = "bundle table of contents is invalid";
+            return false;
+        }
+        if (entry.offset > data.size() ||
+            entry.size > data.size() - entry.offset) {
+            *error_info = util::error(
+                "bundle entry `%s` is out of range", name.c_str());
+            return false;
+        }
+        entry_index_[name] = entry;
+    }
+
+    data_ = &data;
+
+    return true;
+}
+
+bool BundleReader::hasEntry(const std::string &name) const
+{
+    return entry_index_.find(name) != entry_index_.end();
+}
+
+bool BundleReader::readEntry(const std::string &name,
+    std::string *entry_data, std::string *error_info) const
+{
+    EntryIndex::const_iterator iter = entry_index_.find(name);
+    if (iter == entry_index_.end()) {
+        *error_info = util::error(
+            "bundle entry `%s` is not found", name.c_str());
+        return false;
+    }
+    const Entry &entry = iter->second;
+
+    const unsigned char *stored_data =
+        (const unsigned char *)data_->data() + entry.offset;
+    if (::crc32(0, stored_data, entry.size) != entry.crc) {
+        *error_info = util::error(
+            "bundle entry `%s` crc32 is mismatched", name.c_str());
+        return false;
+    }
+
+    if ((entry.flags & s_entry_flag_deflate) == 0) {
+        entry_data->assign((const char *)stored_data, entry.size);
+        return true;
+    }
+
+    // raw deflate stream
+    entry_data->resize(entry.raw_size);
+    z_stream stream;
+    ::memset(&stream, 0, sizeof(stream));
+    if (::inflateInit2(&stream, -15) != Z_OK) {
+        *error_info = "bundle inflate init failed";
+        return false;
+    }
+    stream.next_in = (Bytef *)stored_data;
+    stream.avail_in = entry.size;
+    stream.next_out = (Bytef *)&(*entry_data)[0];
+    stream.avail_out = entry.raw_size;
+    int ret = ::inflate(&stream, Z_FINISH);
+    size_t out_size = entry.raw_size - stream.avail_out;
+    ::inflateEnd(&stream);
+    if (ret != Z_STREAM_END || out_size != entry.raw_size) {
+        *error_info = util::error(
+            "bundle entry `%s` decompress failed", name.c_str());
+        return false;
+    }
+
+    return true;
+}
+
+} // namespace brickred::table
@@ -0,0 +1,44 @@
+#ifndef BRICKRED_TABLE_BUNDLE_READER_H
+#define BRICKRED_TABLE_BUNDLE_READER_H
+
+#include <cstdint>
+#include <string>
+#include <unordered_map>
+
+namespace brickred::table {
+
+class BundleReader final {
+public:
+    BundleReader();
+    ~BundleReader();
+
+    // only save the reference of the string
+    // be attention with string lifetime
+    // schema_fingerprint is generated in table_bundle.h of the reader
+    bool open(const std::string &data,
+        uint32_t schema_fingerprint, std::string *error_info);
+    uint32_t getSchemaFingerprint() const { return schema_fingerprint_; }
+    bool hasEntry(const std::string &name) const;
+    // check crc32 and decompress the entry
+    bool readEntry(const std::string &name,
+        std::string *entry_data, std::string *error_info) const;
+
+private:
+    struct Entry {
+        uint32_t flags;
+        uint32_t offset;
+        uint32_t size;
+        uint32_t raw_size;
+        uint32_t crc;
+    };
+    using EntryIndex = std::unordered_map<std::string, Entry>;
+
+private:
+    const std::string *data_;
+    uint32_t schema_fingerprint_;
+    EntryIndex entry_index_;
+};
+
+} // namespace brickred::table
+
+#endif
@@ -5,7 +5,11 @@
 #include <string>
 #include <vector>
 
+#include <brickred/table/bundle_reader.h>
+
+#include "table_bundle.h"
 #include "tbl_copy.h"
+#include "tbl_global_config.h"
 #include "tbl_item.h"
 #include "tbl_matchmaking.h"
 #include "tbl_npc.h"
@@ -30,6 +34,104 @@ static std::string getTableFileContent(const std::string &file_path)
     return std::string(&input_buffer[0], input_buffer.size());
 }
 
+template <class T>
+static bool parseBinaryTable(T *table,
+    const std::string &bin_dir, const std::string &file_name)
+{
+    std::string error_info;
+    if (table->parseBinary(
+            getTableFileContent(bin_dir + "/" + file_name),
+            &error_info) == false) {
+        ::fprintf(stderr, "parse %s failed: %s\n",
+            file_name.c_str(), error_info.c_str());
+        return false;
+    }
+
+    return true;
+}
+
+static int checkBinaryTables(const std::string &bin_dir,
+    const TblGlobalConfig &tbl_global_config,
+    const TblItem &tbl_item,
+    const TblSkillLevel &tbl_skill_level)
+{
+    TblCopy tbl_copy;
+    TblGlobalConfig bin_tbl_global_config;
+    TblItem bin_tbl_item;
+    TblMatchmaking tbl_matchmaking;
+    TblNpc tbl_npc;
+    TblSkillLevel bin_tbl_skill_level;
+
+    if (parseBinaryTable(&tbl_copy, bin_dir, "copy.bin") == false ||
+        parseBinaryTable(&bin_tbl_global_config,
+            bin_dir, "global_config.bin") == false ||
+        parseBinaryTable(&bin_tbl_item, bin_dir, "item.bin") == false ||
+        parseBinaryTable(&tbl_matchmaking,
+            bin_dir, "matchmaking.bin") == false ||
+        parseBinaryTable(&tbl_npc, bin_dir, "npc.bin") == false ||
+        parseBinaryTable(&bin_tbl_skill_level,
+            bin_dir, "skill_level.bin") == false) {
+        return 1;
+    }
+
+    // binary data should be loaded the same as text data
+    if (bin_tbl_global_config.max_level != tbl_global_config.max_level ||
+        bin_tbl_global_config.init_items.size() !=
+            tbl_global_config.init_items.size() ||
+        bin_tbl_item.getRows().size() != tbl_item.getRows().size() ||
+        bin_tbl_skill_level.getRowSets().size() !=
+            tbl_skill_level.getRowSets().size()) {
+        ::fprintf(stderr, "binary data is different from text data\n");
+        return 1;
+    }
+    for (size_t i = 0; i < tbl_item.getRows().size(); ++i) {
+        const TblItem::Row &row = tbl_item.getRows()[i];
+        const TblItem::Row &bin_row = bin_tbl_item.getRows()[i];
+        if (bin_row.id != row.id || bin_row.name != row.name) {
+            ::fprintf(stderr, "binary data is different from text data\n");
+            return 1;
+        }
+    }
+
+    ::printf("binary tables: ok\n");
+
+    return 0;
+}
+
+static int checkBundleTables(const std::string &bundle_path,
+    const TblItem &tbl_item, const TblSkillLevel &tbl_skill_level)
+{
+    std::string bundle_data = getTableFileContent(bundle_path);
+    brickred::table::BundleReader bundle;
+    std::string error_info;
+
+    if (bundle.open(bundle_data,
+            TableBundle::getSchemaFingerprint(), &error_info) == false) {
+        ::fprintf(stderr, "open %s failed: %s\n",
+            bundle_path.c_str(), error_info.c_str());
+        return 1;
+    }
+
+    TblItem bundle_tbl_item;
+    TblSkillLevel bundle_tbl_skill_level;
+    if (bundle_tbl_item.parseBundle(bundle, &error_info) == false ||
+        bundle_tbl_skill_level.parseBundle(bundle, &error_info) == false) {
+        ::fprintf(stderr, "parse bundle failed: %s\n",
+            error_info.c_str());
+        return 1;
+    }
+    if (bundle_tbl_item.getRows().size() != tbl_item.getRows().size() ||
+        bundle_tbl_skill_level.getRowSets().size() !=
+            tbl_skill_level.getRowSets().size()) {
+        ::fprintf(stderr, "bundle data is different from text data\n");
+        return 1;
+    }
+
+    ::printf("bundle tables: ok\n");
+
+    return 0;
+}
+
 int main(int argc, char *argv[])
 {
     std::string csv_dir = ".";
@@ -38,6 +140,7 @@ int main(int argc, char *argv[])
     }
 
     TblCopy tbl_copy;
+    TblGlobalConfig tbl_global_config;
     TblItem tbl_item;
     TblMatchmaking tbl_matchmaking;
     TblNpc tbl_npc;
@@ -51,6 +154,13 @@ int main(int argc, char *argv[])
             "copy.csv", error_info.c_str());
         return 1;
     }
+    if (tbl_global_config.parse(
+            getTableFileContent(csv_dir + "/global_config.csv"),
+            &error_info) == false) {
+        ::fprintf(stderr, "parse %s failed: %s\n",
+            "global_config.csv", error_info.c_str());
+        return 1;
+    }
     if (tbl_item.parse(
             getTableFileContent(csv_dir + "/item.csv"),
             &error_info) == false) {
@@ -80,6 +190,11 @@ int main(int argc, char *argv[])
         return 1;
     }
 
+    ::printf("tbl_global_config:max_level: %d\n",
+        tbl_global_config.max_level);
+    ::printf("tbl_global_config:init_items:count: %zd\n",
+        tbl_global_config.init_items.size());
+
     {
         const TblMatchmaking::Row *row =
             tbl_matchmaking.getRow(3);
@@ -98,5 +213,17 @@ int main(int argc, char *argv[])
         }
     }
 
+    if (argc > 2) {
+        if (checkBinaryTables(argv[2],
+                tbl_global_config, tbl_item, tbl_skill_level) != 0) {
+            return 1;
+        }
+    }
+    if (argc > 3) {
+        if (checkBundleTables(argv[3], tbl_item, tbl_skill_level) != 0) {
+            return 1;
+        }
+    }
+
     return 0;
 }
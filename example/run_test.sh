@@ -21,6 +21,14 @@ cp "$script_path"/../compiler/bin/brickred-table-compiler .
 if [ $? -ne 0 ]; then exit 1; fi
 cp "$script_path"/../compiler/bin/brickred-table-cutter .
 if [ $? -ne 0 ]; then exit 1; fi
+cp "$script_path"/../compiler/bin/brickred-table-checker .
+if [ $? -ne 0 ]; then exit 1; fi
+cp "$script_path"/../compiler/bin/brickred-table-compat .
+if [ $? -ne 0 ]; then exit 1; fi
+cp "$script_path"/../compiler/bin/brickred-table-exporter .
+if [ $? -ne 0 ]; then exit 1; fi
+cp "$script_path"/../compiler/bin/brickred-table-patcher .
+if [ $? -ne 0 ]; then exit 1; fi
 cp "$script_path"/table.xml .
 if [ $? -ne 0 ]; then exit 1; fi
 cp "$script_path"/main.cc .
@@ -29,7 +37,11 @@ cp "$script_path"/copy.csv .
 if [ $? -ne 0 ]; then exit 1; fi
 cp "$script_path"/effect.csv .
 if [ $? -ne 0 ]; then exit 1; fi
-cp "$script_path"/item.csv .
+cp "$script_path"/global_config.csv .
+if [ $? -ne 0 ]; then exit 1; fi
+cp "$script_path"/item_base.csv .
+if [ $? -ne 0 ]; then exit 1; fi
+cp "$script_path"/item_extra.csv .
 if [ $? -ne 0 ]; then exit 1; fi
 cp "$script_path"/matchmaking.csv .
 if [ $? -ne 0 ]; then exit 1; fi
@@ -38,10 +50,69 @@ if [ $? -ne 0 ]; then exit 1; fi
 cp "$script_path"/skill_level.csv .
 if [ $? -ne 0 ]; then exit 1; fi
 
+# check data
+./brickred-table-checker -f table.xml -i . --lint --check-chars
+if [ $? -ne 0 ]; then exit 1; fi
+
+# check schema compat
+./brickred-table-compat -o table.xml -n table.xml --strict
+if [ $? -ne 0 ]; then exit 1; fi
+
 # cpp test
 mkdir -p server_table
 if [ $? -ne 0 ]; then exit 1; fi
-./brickred-table-cutter -f table.xml -r server -i . -o server_table
+./brickred-table-cutter -f table.xml -r server -i . -o server_table \
+    --manifest --build-version 1
+if [ $? -ne 0 ]; then exit 1; fi
+mkdir -p server_binary
+if [ $? -ne 0 ]; then exit 1; fi
+./brickred-table-exporter -f table.xml -r server -t binary \
+    -i server_table -o server_binary
+if [ $? -ne 0 ]; then exit 1; fi
+mkdir -p server_json
+if [ $? -ne 0 ]; then exit 1; fi
+./brickred-table-exporter -f table.xml -r server -t json \
+    -i server_table -o server_json
+if [ $? -ne 0 ]; then exit 1; fi
+mkdir -p server_lua
+if [ $? -ne 0 ]; then exit 1; fi
+./brickred-table-exporter -f table.xml -r server -t lua \
+    -i server_table -o server_lua
+if [ $? -ne 0 ]; then exit 1; fi
+mkdir -p server_protobuf
+if [ $? -ne 0 ]; then exit 1; fi
+./brickred-table-compiler -f table.xml -l proto -r server -o server_protobuf
+if [ $? -ne 0 ]; then exit 1; fi
+./brickred-table-exporter -f table.xml -r server -t protobuf \
+    -i server_table -o server_protobuf
+if [ $? -ne 0 ]; then exit 1; fi
+mkdir -p server_flatbuffers
+if [ $? -ne 0 ]; then exit 1; fi
+./brickred-table-compiler -f table.xml -l fbs -r server -o server_flatbuffers
+if [ $? -ne 0 ]; then exit 1; fi
+./brickred-table-exporter -f table.xml -r server -t flatbuffers \
+    -i server_table -o server_flatbuffers
+if [ $? -ne 0 ]; then exit 1; fi
+./brickred-table-exporter -f table.xml -r server -t sqlite \
+    -i server_table -o .
+if [ $? -ne 0 ]; then exit 1; fi
+mkdir -p server_msgpack
+if [ $? -ne 0 ]; then exit 1; fi
+./brickred-table-exporter -f table.xml -r server -t msgpack --schema-hash \
+    -i server_table -o server_msgpack
+if [ $? -ne 0 ]; then exit 1; fi
+./brickred-table-exporter -f table.xml -r server -t bundle --compress \
+    -i server_table -o .
+if [ $? -ne 0 ]; then exit 1; fi
+./brickred-table-patcher -f table.xml -r server \
+    --old server_table --new server_table -p server_table.patch
+if [ $? -ne 0 ]; then exit 1; fi
+mkdir -p server_table_patched
+if [ $? -ne 0 ]; then exit 1; fi
+./brickred-table-patcher -f table.xml -r server --apply \
+    --old server_table --new server_table_patched -p server_table.patch
+if [ $? -ne 0 ]; then exit 1; fi
+diff -r server_table server_table_patched
 if [ $? -ne 0 ]; then exit 1; fi
 ./brickred-table-compiler -f table.xml -l cpp -r server
 if [ $? -ne 0 ]; then exit 1; fi
@@ -50,15 +121,19 @@ g++ -I "$script_path"/../cpp/src \
     main.cc \
     resource_item.cc \
     tbl_copy.cc \
+    tbl_global_config.cc \
     tbl_item.cc \
     tbl_matchmaking.cc \
     tbl_npc.cc \
     tbl_skill_level.cc \
+    "$script_path"/../cpp/src/brickred/table/binary_reader.cc \
+    "$script_path"/../cpp/src/brickred/table/bundle_reader.cc \
     "$script_path"/../cpp/src/brickred/table/column_spliter.cc \
     "$script_path"/../cpp/src/brickred/table/line_reader.cc \
-    "$script_path"/../cpp/src/brickred/table/util.cc
+    "$script_path"/../cpp/src/brickred/table/util.cc \
+    -lz
 if [ $? -ne 0 ]; then exit 1; fi
-./cpp_test server_table
+./cpp_test server_table server_binary server.bundle
 if [ $? -ne 0 ]; then exit 1; fi
 
 exit 0